@@ -0,0 +1,165 @@
+//go:build dev
+// +build dev
+
+package assets
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	_ "github.com/oov/psd"
+)
+
+// DevBuild is set when built with -tags dev, images and sounds are then read
+// from the files listed in configs/assets.toml instead of gen.go and images are
+// reloaded whenever their file changes
+const DevBuild = true
+
+const (
+	// devRootEnv points at the repo when the game isn't run from it
+	devRootEnv      = "WALK_GOOD_ROOT"
+	devPollInterval = 500 * time.Millisecond
+)
+
+type devFile struct {
+	path    string
+	scale   int
+	modTime time.Time
+	watched bool
+}
+
+var dev struct {
+	sync.Mutex
+	once    sync.Once
+	images  map[string]*devFile
+	sounds  map[string]*devFile
+	changed []string
+}
+
+func devLoad() {
+	dev.once.Do(func() {
+		dev.images = make(map[string]*devFile)
+		dev.sounds = make(map[string]*devFile)
+
+		root := os.Getenv(devRootEnv)
+		if root == "" {
+			root = "."
+		}
+
+		var config struct {
+			Images []struct {
+				Name            string
+				ScaleMultiplier int
+				File            string
+			}
+			Music []struct {
+				Name string
+				File string
+			}
+			Sounds []struct {
+				Name string
+				File string
+			}
+		}
+		if _, err := toml.DecodeFile(filepath.Join(root, "configs", "assets.toml"), &config); err != nil {
+			log.Printf("using built in assets can't read assets config: %v", err)
+			return
+		}
+
+		assetsPath := filepath.Join(root, "assets")
+		for _, image := range config.Images {
+			scale := image.ScaleMultiplier
+			if scale == 0 {
+				scale = 1
+			}
+			dev.images[image.Name] = &devFile{path: filepath.Join(assetsPath, "images", image.File), scale: scale}
+		}
+		for _, music := range config.Music {
+			dev.sounds[music.Name] = &devFile{path: filepath.Join(assetsPath, "music", music.File)}
+		}
+		for _, sound := range config.Sounds {
+			dev.sounds[sound.Name] = &devFile{path: filepath.Join(assetsPath, "sounds", sound.File)}
+		}
+
+		go devWatch()
+	})
+}
+
+// devRead reads the file for name and starts watching it
+func devRead(files map[string]*devFile, name string) ([]byte, *devFile, bool) {
+	dev.Lock()
+	defer dev.Unlock()
+
+	file, ok := files[name]
+	if !ok {
+		return nil, nil, false
+	}
+
+	data, err := ioutil.ReadFile(file.path)
+	if err != nil {
+		log.Printf("using built in %s: %v", name, err)
+		return nil, nil, false
+	}
+
+	if info, err := os.Stat(file.path); err == nil {
+		file.modTime = info.ModTime()
+		file.watched = true
+	}
+
+	return data, file, true
+}
+
+func devImage(name string) ([]byte, int, bool) {
+	devLoad()
+	data, file, ok := devRead(dev.images, name)
+	if !ok {
+		return nil, 0, false
+	}
+
+	return data, file.scale, true
+}
+
+// devSound isn't watched since sounds are read again every time they're loaded
+func devSound(name string) ([]byte, bool) {
+	devLoad()
+	data, _, ok := devRead(dev.sounds, name)
+
+	return data, ok
+}
+
+// devChanged is the images whose file changed since it was last called
+func devChanged() []string {
+	dev.Lock()
+	defer dev.Unlock()
+
+	result := dev.changed
+	dev.changed = nil
+
+	return result
+}
+
+func devWatch() {
+	for range time.Tick(devPollInterval) {
+		dev.Lock()
+		for name, file := range dev.images {
+			if !file.watched {
+				continue
+			}
+
+			info, err := os.Stat(file.path)
+			if err != nil || info.ModTime().Equal(file.modTime) {
+				continue
+			}
+
+			file.modTime = info.ModTime()
+			dev.changed = append(dev.changed, name)
+		}
+		dev.Unlock()
+	}
+}
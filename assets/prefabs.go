@@ -0,0 +1,15 @@
+package assets
+
+import (
+	_ "embed"
+)
+
+// Prefabs is read by entity.DefaultPrefabs
+//
+//go:embed prefabs.toml
+var Prefabs []byte
+
+// Hitboxes is read by entity.DefaultHitboxes
+//
+//go:embed hitboxes.toml
+var Hitboxes []byte
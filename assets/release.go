@@ -0,0 +1,19 @@
+//go:build !dev
+// +build !dev
+
+package assets
+
+// DevBuild is set when built with -tags dev
+const DevBuild = false
+
+func devImage(string) ([]byte, int, bool) {
+	return nil, 0, false
+}
+
+func devSound(string) ([]byte, bool) {
+	return nil, false
+}
+
+func devChanged() []string {
+	return nil
+}
@@ -1,127 +1,291 @@
-package assets
-
-import (
-	"bytes"
-	"compress/gzip"
-	"crypto/md5"
-	"encoding/json"
-	"image"
-	"io/ioutil"
-	"reflect"
-	"sync"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/nfnt/resize"
-
-	"image/color"
-	_ "image/png"
-)
-
-var (
-	imageCache map[[16]byte]*ebiten.Image
-	lock       *sync.Mutex
-)
-
-func init() {
-	imageCache = make(map[[16]byte]*ebiten.Image)
-	lock = &sync.Mutex{}
-}
-
-func getImageHash(data []byte, clrMap map[color.RGBA]color.RGBA) [16]byte {
-	result := md5.Sum(data)
-	if clrMap != nil {
-		// LOOK: slowpoint
-		buf := &bytes.Buffer{}
-		je := json.NewEncoder(buf)
-
-		for key, value := range clrMap {
-			je.Encode(key)
-			je.Encode(value)
-		}
-
-		buf.Write(result[:])
-		result = md5.Sum(buf.Bytes())
-	}
-
-	return result
-}
-
-func LoadEbitenImageColorSwap(asset interface{}, clrMap map[color.RGBA]color.RGBA) (*ebiten.Image, error) {
-	t := reflect.ValueOf(asset)
-
-	data := []byte(t.FieldByName("Data").String())
-
-	hash := getImageHash(data, clrMap)
-
-	lock.Lock()
-	defer lock.Unlock()
-	eImg, ok := imageCache[hash]
-	if ok {
-		return eImg, nil
-	}
-
-	compressed := t.FieldByName("Compressed").Bool()
-	scale := int(t.FieldByName("ScaleMultiplier").Int())
-
-	if compressed {
-		zr, _ := gzip.NewReader(bytes.NewReader(data))
-		defer zr.Close()
-		var err error
-		data, err = ioutil.ReadAll(zr)
-		if err != nil {
-			panic(err)
-		}
-	}
-
-	img, _, err := image.Decode(bytes.NewReader(data))
-	if err != nil {
-		return nil, err
-	}
-	img = resize.Resize(uint(img.Bounds().Dx()*scale), uint(img.Bounds().Dy()*scale), img, resize.NearestNeighbor)
-
-	eImg = ebiten.NewImageFromImage(img)
-	if clrMap != nil {
-		for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
-			for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
-				mappedClr, ok := clrMap[eImg.At(x, y).(color.RGBA)]
-				if ok {
-					eImg.Set(x, y, mappedClr)
-				}
-			}
-		}
-	}
-
-	imageCache[hash] = eImg
-
-	return eImg, nil
-}
-
-func LoadEbitenImage(asset interface{}) (*ebiten.Image, error) {
-	return LoadEbitenImageColorSwap(asset, nil)
-}
-
-func LoadSound(asset interface{}) (data []byte, sampleRate int, soundType SoundType) {
-	t := reflect.ValueOf(asset)
-
-	sampleRate = int(t.FieldByName("SampleRate").Int())
-	data = []byte(t.FieldByName("Data").String())
-	soundType = SoundType(t.FieldByName("SoundType").Int())
-
-	return
-}
-
-func LoadKaraoke(asset interface{}) (data []byte) {
-	t := reflect.ValueOf(asset)
-
-	data = []byte(t.FieldByName("JsonStr").String())
-
-	zr, _ := gzip.NewReader(bytes.NewReader(data))
-	defer zr.Close()
-	var err error
-	data, err = ioutil.ReadAll(zr)
-	if err != nil {
-		panic(err)
-	}
-
-	return
-}
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/json"
+	"image"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/nfnt/resize"
+
+	"image/color"
+	_ "image/png"
+)
+
+var (
+	imageCache map[[16]byte]*ebiten.Image
+	// imageSources is what was loaded for each asset so it can be remade when
+	// the file changes, only kept in dev builds
+	imageSources map[string][]*imageSource
+	// reloaded is every reload a watcher hasn't seen yet, reloadedBase is how
+	// many were let go from the front
+	reloaded     []ReloadedImage
+	reloadedBase int
+	watchers     map[*ReloadWatcher]struct{}
+	lock         *sync.Mutex
+)
+
+type imageSource struct {
+	clrMap map[color.RGBA]color.RGBA
+	img    *ebiten.Image
+}
+
+// ReloadedImage is an image which changed on disk, anything still drawing Old
+// should swap to New
+type ReloadedImage struct {
+	Old *ebiten.Image
+	New *ebiten.Image
+}
+
+func init() {
+	imageCache = make(map[[16]byte]*ebiten.Image)
+	imageSources = make(map[string][]*imageSource)
+	watchers = make(map[*ReloadWatcher]struct{})
+	lock = &sync.Mutex{}
+}
+
+func getImageHash(data []byte, clrMap map[color.RGBA]color.RGBA) [16]byte {
+	result := md5.Sum(data)
+	if clrMap != nil {
+		// LOOK: slowpoint
+		buf := &bytes.Buffer{}
+		je := json.NewEncoder(buf)
+
+		for key, value := range clrMap {
+			je.Encode(key)
+			je.Encode(value)
+		}
+
+		buf.Write(result[:])
+		result = md5.Sum(buf.Bytes())
+	}
+
+	return result
+}
+
+// Name is the name from assets.toml, assets generated before names were
+// added don't have one
+func Name(asset interface{}) string {
+	field := reflect.ValueOf(asset).FieldByName("Name")
+	if !field.IsValid() {
+		return ""
+	}
+
+	return field.String()
+}
+
+func decodeImage(data []byte, compressed bool, scale int, clrMap map[color.RGBA]color.RGBA) (*ebiten.Image, error) {
+	if compressed {
+		zr, _ := gzip.NewReader(bytes.NewReader(data))
+		defer zr.Close()
+		var err error
+		data, err = ioutil.ReadAll(zr)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	img = resize.Resize(uint(img.Bounds().Dx()*scale), uint(img.Bounds().Dy()*scale), img, resize.NearestNeighbor)
+
+	eImg := ebiten.NewImageFromImage(img)
+	if clrMap != nil {
+		for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+			for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+				mappedClr, ok := clrMap[eImg.At(x, y).(color.RGBA)]
+				if ok {
+					eImg.Set(x, y, mappedClr)
+				}
+			}
+		}
+	}
+
+	return eImg, nil
+}
+
+func LoadEbitenImageColorSwap(asset interface{}, clrMap map[color.RGBA]color.RGBA) (*ebiten.Image, error) {
+	t := reflect.ValueOf(asset)
+
+	name := Name(asset)
+	data := []byte(t.FieldByName("Data").String())
+	compressed := t.FieldByName("Compressed").Bool()
+	scale := int(t.FieldByName("ScaleMultiplier").Int())
+
+	if fileData, fileScale, ok := devImage(name); ok {
+		data, compressed, scale = fileData, false, fileScale
+	}
+
+	hash := getImageHash(data, clrMap)
+
+	lock.Lock()
+	defer lock.Unlock()
+	eImg, ok := imageCache[hash]
+	if ok {
+		return eImg, nil
+	}
+
+	eImg, err := decodeImage(data, compressed, scale, clrMap)
+	if err != nil {
+		return nil, err
+	}
+
+	imageCache[hash] = eImg
+	if DevBuild && name != "" {
+		imageSources[name] = append(imageSources[name], &imageSource{clrMap: clrMap, img: eImg})
+	}
+
+	return eImg, nil
+}
+
+func LoadEbitenImage(asset interface{}) (*ebiten.Image, error) {
+	return LoadEbitenImageColorSwap(asset, nil)
+}
+
+// FrameWidth is the width of a single frame for tile sets after scaling, plain
+// images have no frames so it's 0 for them
+func FrameWidth(asset interface{}) int {
+	field := reflect.ValueOf(asset).FieldByName("FrameWidth")
+	if !field.IsValid() {
+		return 0
+	}
+
+	return int(field.Int())
+}
+
+// ScaleMultiplier is how many times bigger the image is than its file, 1 for
+// anything without one
+func ScaleMultiplier(asset interface{}) int {
+	field := reflect.ValueOf(asset).FieldByName("ScaleMultiplier")
+	if !field.IsValid() || field.Int() == 0 {
+		return 1
+	}
+
+	return int(field.Int())
+}
+
+func LoadSound(asset interface{}) (data []byte, sampleRate int, soundType SoundType) {
+	t := reflect.ValueOf(asset)
+
+	sampleRate = int(t.FieldByName("SampleRate").Int())
+	data = []byte(t.FieldByName("Data").String())
+	soundType = SoundType(t.FieldByName("SoundType").Int())
+
+	if fileData, ok := devSound(Name(asset)); ok {
+		data = fileData
+	}
+
+	return
+}
+
+// ReloadWatcher is how far one user of reloads has got, reloads every watcher
+// has seen are let go
+type ReloadWatcher struct {
+	seen int
+}
+
+// WatchReloads starts watching from now, anything loaded after this is already
+// fresh. Stop it once done or reloads are kept around for it forever
+func WatchReloads() *ReloadWatcher {
+	lock.Lock()
+	defer lock.Unlock()
+
+	result := &ReloadWatcher{seen: reloadedBase + len(reloaded)}
+	watchers[result] = struct{}{}
+
+	return result
+}
+
+// Reloads remakes the images of any files changed on disk then returns every
+// image reloaded since w last asked. Only dev builds ever reload anything
+func (w *ReloadWatcher) Reloads() []ReloadedImage {
+	lock.Lock()
+	defer lock.Unlock()
+
+	reloadChanged()
+
+	// Stopped ones already let go of what they hadn't seen
+	if _, ok := watchers[w]; !ok {
+		return nil
+	}
+
+	result := append([]ReloadedImage(nil), reloaded[w.seen-reloadedBase:]...)
+	w.seen = reloadedBase + len(reloaded)
+	trimReloaded()
+
+	return result
+}
+
+func (w *ReloadWatcher) Stop() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	delete(watchers, w)
+	trimReloaded()
+}
+
+// trimReloaded lets go of the reloads every watcher has seen
+func trimReloaded() {
+	oldest := reloadedBase + len(reloaded)
+	for w := range watchers {
+		if w.seen < oldest {
+			oldest = w.seen
+		}
+	}
+
+	if oldest == reloadedBase {
+		return
+	}
+
+	// Copied so the old images aren't kept alive by the array behind it
+	reloaded = append([]ReloadedImage(nil), reloaded[oldest-reloadedBase:]...)
+	reloadedBase = oldest
+}
+
+// reloadChanged remakes the images of any files changed on disk
+func reloadChanged() {
+
+	for _, name := range devChanged() {
+		data, scale, ok := devImage(name)
+		if !ok {
+			continue
+		}
+
+		for _, source := range imageSources[name] {
+			img, err := decodeImage(data, false, scale, source.clrMap)
+			if err != nil {
+				// Most likely caught half saved it will change again
+				log.Printf("can't reload %s: %v", name, err)
+				continue
+			}
+
+			imageCache[getImageHash(data, source.clrMap)] = img
+			reloaded = append(reloaded, ReloadedImage{Old: source.img, New: img})
+			source.img = img
+		}
+	}
+}
+
+func LoadKaraoke(asset interface{}) (data []byte) {
+	t := reflect.ValueOf(asset)
+
+	data = []byte(t.FieldByName("JsonStr").String())
+
+	zr, _ := gzip.NewReader(bytes.NewReader(data))
+	defer zr.Close()
+	var err error
+	data, err = ioutil.ReadAll(zr)
+	if err != nil {
+		panic(err)
+	}
+
+	return
+}
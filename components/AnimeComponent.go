@@ -1,9 +1,9 @@
-package components
-
-import "time"
-
-type AnimeComponent struct {
-	FrameDuration  time.Duration
-	FrameRemaining time.Duration
-	Cycles         int
-}
+package components
+
+import "time"
+
+type AnimeComponent struct {
+	FrameDuration  time.Duration
+	FrameRemaining time.Duration
+	Cycles         int
+}
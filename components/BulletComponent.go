@@ -1,7 +1,7 @@
-package components
-
-import "github.com/sardap/walk-good-maybe-hd/math"
-
-type BulletComponent struct {
-	Speed math.Vector2
-}
+package components
+
+import "github.com/sardap/walk-good-maybe-hd/math"
+
+type BulletComponent struct {
+	Speed math.Vector2
+}
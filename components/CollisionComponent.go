@@ -1,29 +1,98 @@
-package components
-
-import (
-	"github.com/SolarLune/resolv"
-	"github.com/sardap/walk-good-maybe-hd/utility"
-)
-
-type CollisionEvent struct {
-	ID   uint64
-	Tags []int
-}
-
-type CollisionEvents []*CollisionEvent
-
-func (c CollisionEvents) CollidingWith(tags ...int) bool {
-	for _, event := range c {
-		if utility.ContainsInt(event.Tags, tags...) {
-			return true
-		}
-	}
-
-	return false
-}
-
-type CollisionComponent struct {
-	Active         bool
-	Collisions     CollisionEvents
-	CollisionShape *resolv.Rectangle
-}
+package components
+
+import (
+	"github.com/SolarLune/resolv"
+	"github.com/sardap/walk-good-maybe-hd/math"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+)
+
+type CollisionEvent struct {
+	// ID is the entity touched
+	ID   uint64
+	Tags []int
+	// Normal is the side of the other thing touched pointing out of it so
+	// {0, -1} is landing on top of it
+	Normal math.Vector2
+	// Depth is how far into it along Normal
+	Depth float64
+}
+
+type CollisionEvents []*CollisionEvent
+
+func (c CollisionEvents) CollidingWith(tags ...int) bool {
+	for _, event := range c {
+		if utility.ContainsInt(event.Tags, tags...) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OnTopOf is if any of the collisions with tags were landing on top
+func (c CollisionEvents) OnTopOf(tags ...int) bool {
+	for _, event := range c {
+		if event.Normal.Y < 0 && utility.ContainsInt(event.Tags, tags...) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CollisionLayer is a bit for each kind of collidable, masks are layers or'd
+// together
+type CollisionLayer uint32
+
+// Has is if any of layers are in the mask
+func (c CollisionLayer) Has(layers CollisionLayer) bool {
+	return c&layers != 0
+}
+
+// HitboxRect is relative to the top left of whatever it's on
+type HitboxRect struct {
+	X, Y, W, H float64
+}
+
+// Hitbox is the parts of something that can touch things, Frames replaces
+// Rects on those frames of its animation
+type Hitbox struct {
+	Rects  []HitboxRect
+	Frames map[int][]HitboxRect
+}
+
+// At is the rects for frame
+func (h *Hitbox) At(frame int) []HitboxRect {
+	if rects, ok := h.Frames[frame]; ok {
+		return rects
+	}
+
+	return h.Rects
+}
+
+type CollisionComponent struct {
+	Active bool
+	// Swept checks everything passed on the way from where it was last frame,
+	// for things fast enough to skip over others
+	Swept bool
+	// OneWay is only solid from above like a ledge, things can jump up
+	// through it and walk through its sides
+	OneWay bool
+	// CollisionLayer is what it is, it can't move through anything in Blocks
+	// and hears about touching anything in Blocks or Senses
+	CollisionLayer CollisionLayer
+	Blocks         CollisionLayer
+	Senses         CollisionLayer
+	// Hitbox is where it can touch things, all of its Size when nil
+	Hitbox     *Hitbox
+	Collisions CollisionEvents
+	// CollisionShape is the first of CollisionShapes which has a rect for
+	// each part of the hitbox
+	CollisionShape  *resolv.Rectangle
+	CollisionShapes *resolv.Space
+}
+
+// Notices is if it should hear about touching something on layer
+func (c *CollisionComponent) Notices(layer CollisionLayer) bool {
+	return (c.Blocks | c.Senses).Has(layer)
+}
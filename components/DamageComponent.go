@@ -1,6 +1,10 @@
-package components
-
-type DamageComponent struct {
-	BaseDamage float64
-	DamageMap  map[string]float64
-}
+package components
+
+type DamageComponent struct {
+	BaseDamage float64
+	// StompDamage is done instead landing on top of anything on a layer in
+	// Stomps, which doesn't get to hurt back
+	StompDamage float64
+	Stomps      CollisionLayer
+	DamageMap   map[string]float64
+}
@@ -1,5 +1,5 @@
-package components
-
-type DestoryOnAnimeComponent struct {
-	CyclesTilDeath int
-}
+package components
+
+type DestoryOnAnimeComponent struct {
+	CyclesTilDeath int
+}
@@ -1,4 +1,4 @@
-package components
-
-type GravityComponent struct {
-}
+package components
+
+type GravityComponent struct {
+}
@@ -1,25 +1,33 @@
-package components
-
-import "time"
-
-type MainGamePlayerState int
-
-// Idk if this should live here
-const (
-	MainGamePlayerStateGroundIdling MainGamePlayerState = iota
-	MainGamePlayerStateGroundMoving
-	MainGamePlayerStateFlying
-	MainGamePlayerStatePrepareJumping
-	MainGamePlayerStateJumping
-)
-
-type MainGamePlayerComponent struct {
-	State                 MainGamePlayerState
-	Speed                 float64
-	AirHorzSpeedModifier  float64
-	JumpPower             float64
-	JumpPowerRemaning     float64
-	JumpTime              time.Duration
-	ShootCooldown         time.Duration
-	ShootCooldownRemaning time.Duration
-}
+package components
+
+import "time"
+
+type MainGamePlayerState int
+
+// Idk if this should live here
+const (
+	MainGamePlayerStateGroundIdling MainGamePlayerState = iota
+	MainGamePlayerStateGroundMoving
+	MainGamePlayerStateFlying
+	MainGamePlayerStatePrepareJumping
+	MainGamePlayerStateJumping
+	MainGamePlayerStateBouncing
+)
+
+type MainGamePlayerComponent struct {
+	State                 MainGamePlayerState
+	Speed                 float64
+	AirHorzSpeedModifier  float64
+	JumpPower             float64
+	JumpPowerRemaning     float64
+	JumpTime              time.Duration
+	ShootCooldown         time.Duration
+	ShootCooldownRemaning time.Duration
+	// CoyoteTime is how long after running off something jumping still works
+	CoyoteTime     time.Duration
+	CoyoteRemaning time.Duration
+	// JumpBufferTime is how long a jump pressed in the air is remembered for
+	// so it still happens if it was pressed just before landing
+	JumpBufferTime     time.Duration
+	JumpBufferRemaning time.Duration
+}
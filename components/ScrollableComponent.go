@@ -1,5 +1,5 @@
-package components
-
-type ScrollableComponent struct {
-	Modifier float64
-}
+package components
+
+type ScrollableComponent struct {
+	Modifier float64
+}
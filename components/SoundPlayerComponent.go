@@ -1,4 +1,4 @@
-package components
-
-type SoundPlayerComponent struct {
-}
+package components
+
+type SoundPlayerComponent struct {
+}
@@ -0,0 +1,34 @@
+package components
+
+import "time"
+
+// Timer calls OnDone once Remaining runs out, it's advanced by the timer
+// system so it pauses with the world
+type Timer struct {
+	Remaining time.Duration
+	OnDone    func()
+	stopped   bool
+}
+
+// Stop cancels the timer without calling OnDone
+func (t *Timer) Stop() {
+	t.stopped = true
+}
+
+func (t *Timer) Stopped() bool {
+	return t.stopped
+}
+
+type TimerComponent struct {
+	Timers []*Timer
+}
+
+func (t *TimerComponent) AddTimer(duration time.Duration, onDone func()) *Timer {
+	timer := &Timer{
+		Remaining: duration,
+		OnDone:    onDone,
+	}
+	t.Timers = append(t.Timers, timer)
+
+	return timer
+}
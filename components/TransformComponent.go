@@ -1,10 +1,10 @@
-package components
-
-import (
-	"github.com/sardap/walk-good-maybe-hd/math"
-)
-
-type TransformComponent struct {
-	Postion math.Vector2
-	Size    math.Vector2
-}
+package components
+
+import (
+	"github.com/sardap/walk-good-maybe-hd/math"
+)
+
+type TransformComponent struct {
+	Postion math.Vector2
+	Size    math.Vector2
+}
@@ -0,0 +1,74 @@
+package components
+
+import "time"
+
+type EaseFunc func(t float64) float64
+
+func EaseLinear(t float64) float64 {
+	return t
+}
+
+func EaseInOutQuad(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+
+	return 1 - (-2*t+2)*(-2*t+2)/2
+}
+
+// Tween moves a value from From to To over Duration calling Set with the
+// value every update
+type Tween struct {
+	From     float64
+	To       float64
+	Duration time.Duration
+	Elapsed  time.Duration
+	// Ease defaults to linear
+	Ease    EaseFunc
+	Set     func(float64)
+	OnDone  func()
+	stopped bool
+}
+
+func (t *Tween) Stop() {
+	t.stopped = true
+}
+
+func (t *Tween) Stopped() bool {
+	return t.stopped
+}
+
+// Value is where the tween is at for it's elapsed time
+func (t *Tween) Value() float64 {
+	progress := float64(1)
+	if t.Duration > 0 && t.Elapsed < t.Duration {
+		progress = float64(t.Elapsed) / float64(t.Duration)
+	}
+
+	ease := t.Ease
+	if ease == nil {
+		ease = EaseLinear
+	}
+
+	return t.From + (t.To-t.From)*ease(progress)
+}
+
+func (t *Tween) Done() bool {
+	return t.Elapsed >= t.Duration
+}
+
+type TweenComponent struct {
+	Tweens []*Tween
+}
+
+func (t *TweenComponent) AddTween(from, to float64, duration time.Duration, set func(float64)) *Tween {
+	tween := &Tween{
+		From:     from,
+		To:       to,
+		Duration: duration,
+		Set:      set,
+	}
+	t.Tweens = append(t.Tweens, tween)
+
+	return tween
+}
@@ -1,8 +1,8 @@
-package components
-
-import "time"
-
-type UfoBiscuitEnemyComponent struct {
-	ShootTime         time.Duration
-	ShootTimeRemaning time.Duration
-}
+package components
+
+import "time"
+
+type UfoBiscuitEnemyComponent struct {
+	ShootTime         time.Duration
+	ShootTimeRemaning time.Duration
+}
@@ -1,9 +1,9 @@
-package components
-
-import (
-	"github.com/sardap/walk-good-maybe-hd/math"
-)
-
-type VelocityComponent struct {
-	Vel math.Vector2
-}
+package components
+
+import (
+	"github.com/sardap/walk-good-maybe-hd/math"
+)
+
+type VelocityComponent struct {
+	Vel math.Vector2
+}
@@ -1,8 +1,8 @@
-package components
-
-import "github.com/sardap/walk-good-maybe-hd/math"
-
-type WrapComponent struct {
-	Max math.Vector2
-	Min math.Vector2
-}
+package components
+
+import "github.com/sardap/walk-good-maybe-hd/math"
+
+type WrapComponent struct {
+	Max math.Vector2
+	Min math.Vector2
+}
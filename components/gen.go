@@ -170,6 +170,14 @@ type TileImageFace interface {
 	GetTileImageComponent() *TileImageComponent
 }
 
+func (t *TimerComponent) GetTimerComponent() *TimerComponent {
+	return t
+}
+
+type TimerFace interface {
+	GetTimerComponent() *TimerComponent
+}
+
 func (t *TransformComponent) GetTransformComponent() *TransformComponent {
 	return t
 }
@@ -178,6 +186,14 @@ type TransformFace interface {
 	GetTransformComponent() *TransformComponent
 }
 
+func (t *TweenComponent) GetTweenComponent() *TweenComponent {
+	return t
+}
+
+type TweenFace interface {
+	GetTweenComponent() *TweenComponent
+}
+
 func (u *UfoBiscuitEnemyComponent) GetUfoBiscuitEnemyComponent() *UfoBiscuitEnemyComponent {
 	return u
 }
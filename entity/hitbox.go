@@ -0,0 +1,148 @@
+package entity
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sardap/walk-good-maybe-hd/assets"
+	"github.com/sardap/walk-good-maybe-hd/components"
+)
+
+type hitboxRect struct {
+	X prefabFloat `toml:"x"`
+	Y prefabFloat `toml:"y"`
+	W prefabFloat `toml:"w"`
+	H prefabFloat `toml:"h"`
+}
+
+type hitboxDef struct {
+	Image  string       `toml:"image"`
+	Rects  []hitboxRect `toml:"rects"`
+	Frames []struct {
+		Frame int          `toml:"frame"`
+		Rects []hitboxRect `toml:"rects"`
+	} `toml:"frames"`
+}
+
+// Hitboxes are by image name already scaled up to the size the image is
+// drawn at
+type Hitboxes struct {
+	byImage map[string]*components.Hitbox
+}
+
+func scaleHitboxRects(rects []hitboxRect, scale float64) ([]components.HitboxRect, error) {
+	if len(rects) == 0 {
+		return nil, fmt.Errorf("needs at least one rect")
+	}
+
+	result := make([]components.HitboxRect, len(rects))
+	for i, rect := range rects {
+		if rect.W <= 0 || rect.H <= 0 {
+			return nil, fmt.Errorf("rect %d has no area", i)
+		}
+
+		result[i] = components.HitboxRect{
+			X: float64(rect.X) * scale,
+			Y: float64(rect.Y) * scale,
+			W: float64(rect.W) * scale,
+			H: float64(rect.H) * scale,
+		}
+	}
+
+	return result, nil
+}
+
+func (d *hitboxDef) hitbox() (*components.Hitbox, error) {
+	asset, ok := assets.Images[d.Image]
+	if !ok {
+		return nil, fmt.Errorf("unknown image %q", d.Image)
+	}
+	scale := float64(assets.ScaleMultiplier(asset))
+
+	rects, err := scaleHitboxRects(d.Rects, scale)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &components.Hitbox{
+		Rects: rects,
+	}
+
+	for _, frame := range d.Frames {
+		if frame.Frame < 0 {
+			return nil, fmt.Errorf("frame %d can't be negative", frame.Frame)
+		}
+
+		if _, ok := result.Frames[frame.Frame]; ok {
+			return nil, fmt.Errorf("frame %d is defined twice", frame.Frame)
+		}
+
+		rects, err := scaleHitboxRects(frame.Rects, scale)
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", frame.Frame, err)
+		}
+
+		if result.Frames == nil {
+			result.Frames = make(map[int][]components.HitboxRect)
+		}
+		result.Frames[frame.Frame] = rects
+	}
+
+	return result, nil
+}
+
+func LoadHitboxes(data []byte) (*Hitboxes, error) {
+	var file struct {
+		Hitboxes []*hitboxDef
+	}
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return nil, err
+	}
+
+	result := &Hitboxes{
+		byImage: make(map[string]*components.Hitbox),
+	}
+	for i, def := range file.Hitboxes {
+		if def.Image == "" {
+			return nil, fmt.Errorf("hitbox %d has no image", i)
+		}
+
+		if _, ok := result.byImage[def.Image]; ok {
+			return nil, fmt.Errorf("hitbox for %s is defined twice", def.Image)
+		}
+
+		hitbox, err := def.hitbox()
+		if err != nil {
+			return nil, fmt.Errorf("hitbox %s: %w", def.Image, err)
+		}
+
+		result.byImage[def.Image] = hitbox
+	}
+
+	return result, nil
+}
+
+var (
+	defaultHitboxes     *Hitboxes
+	defaultHitboxesOnce sync.Once
+)
+
+// DefaultHitboxes are the ones built into the game from assets/hitboxes.toml
+func DefaultHitboxes() *Hitboxes {
+	defaultHitboxesOnce.Do(func() {
+		var err error
+		defaultHitboxes, err = LoadHitboxes(assets.Hitboxes)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	return defaultHitboxes
+}
+
+// For is the hitbox for things drawn with image, nil if it doesn't have one
+// so it's the whole image
+func (h *Hitboxes) For(image string) *components.Hitbox {
+	return h.byImage[image]
+}
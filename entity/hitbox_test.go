@@ -0,0 +1,103 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultHitboxes(t *testing.T) {
+	t.Parallel()
+
+	hitboxes := DefaultHitboxes()
+
+	whale := hitboxes.For("whaleAir")
+	if assert.NotNil(t, whale) {
+		assert.Equal(t, []components.HitboxRect{
+			{X: 0, Y: 10, W: 160, H: 90},
+			{X: 50, Y: 100, W: 90, H: 60},
+		}, whale.Rects, "should be scaled like the image")
+	}
+	assert.Equal(t, whale, CreatePlayer().Hitbox)
+
+	for _, name := range []string{"whaleIdle", "whaleWalk", "whaleJump"} {
+		if assert.NotNilf(t, hitboxes.For(name), "%s needs a hitbox since the player swaps to it", name) {
+			rects := hitboxes.For(name).Rects
+			assert.Equalf(t, float64(160), rects[len(rects)-1].Y+rects[len(rects)-1].H, "%s feet should be where they are in the air", name)
+		}
+	}
+	assert.Equal(t, float64(40), hitboxes.For("whaleJump").At(1)[0].Y, "should crouch with the animation")
+
+	ufo := hitboxes.For("biscutUFOIdle")
+	if assert.NotNil(t, ufo) {
+		assert.Equal(t, float64(40), ufo.At(0)[1].Y)
+		assert.Equal(t, float64(0), ufo.At(2)[1].Y, "pilot should rise with the animation")
+		assert.Equal(t, ufo.Rects, ufo.At(10))
+	}
+
+	assert.Nil(t, hitboxes.For("tokenJumpUp"))
+}
+
+func TestLoadHitboxes(t *testing.T) {
+	t.Parallel()
+
+	hitboxes, err := LoadHitboxes([]byte(`
+[[Hitboxes]]
+image="tokenJumpUp"
+rects=[{x=1, y=2, w=3.5, h=4}]
+
+[[Hitboxes.frames]]
+frame=1
+rects=[{x=0, y=0, w=1, h=1}, {x=2, y=2, w=1, h=1}]
+`))
+	assert.NoError(t, err)
+
+	token := hitboxes.For("tokenJumpUp")
+	if assert.NotNil(t, token) {
+		assert.Equal(t, []components.HitboxRect{{X: 2, Y: 4, W: 7, H: 8}}, token.At(0))
+		assert.Len(t, token.At(1), 2)
+	}
+
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{name: "no image", data: `[[Hitboxes]]
+rects=[{x=0, y=0, w=1, h=1}]`},
+		{name: "unknown image", data: `[[Hitboxes]]
+image="dragon"
+rects=[{x=0, y=0, w=1, h=1}]`},
+		{name: "no rects", data: `[[Hitboxes]]
+image="tokenJumpUp"`},
+		{name: "no area", data: `[[Hitboxes]]
+image="tokenJumpUp"
+rects=[{x=0, y=0, w=0, h=1}]`},
+		{name: "negative frame", data: `[[Hitboxes]]
+image="tokenJumpUp"
+rects=[{x=0, y=0, w=1, h=1}]
+[[Hitboxes.frames]]
+frame=-1
+rects=[{x=0, y=0, w=1, h=1}]`},
+		{name: "frame twice", data: `[[Hitboxes]]
+image="tokenJumpUp"
+rects=[{x=0, y=0, w=1, h=1}]
+[[Hitboxes.frames]]
+frame=1
+rects=[{x=0, y=0, w=1, h=1}]
+[[Hitboxes.frames]]
+frame=1
+rects=[{x=0, y=0, w=1, h=1}]`},
+		{name: "twice", data: `[[Hitboxes]]
+image="tokenJumpUp"
+rects=[{x=0, y=0, w=1, h=1}]
+[[Hitboxes]]
+image="tokenJumpUp"
+rects=[{x=0, y=0, w=1, h=1}]`},
+	}
+
+	for _, testCase := range testCases {
+		_, err := LoadHitboxes([]byte(testCase.data))
+		assert.Errorf(t, err, testCase.name)
+	}
+}
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"github.com/sardap/walk-good-maybe-hd/components"
+)
+
+const (
+	LayerGround components.CollisionLayer = 1 << iota
+	LayerPlayer
+	LayerEnemy
+	// LayerCorpse is for dead things left lying around
+	LayerCorpse
+	LayerBullet
+	LayerToken
+	// LayerHazard kills whatever falls into it
+	LayerHazard
+)
+
+type CollisionRule struct {
+	// Blocks is what it can't move through
+	Blocks components.CollisionLayer
+	// Senses is what it can move through but still wants to know it touched
+	Senses components.CollisionLayer
+}
+
+// collisionRules is how every layer collides with the others, a layer not in
+// here doesn't collide with anything
+var collisionRules = map[components.CollisionLayer]CollisionRule{
+	LayerGround: {},
+	LayerPlayer: {Blocks: LayerGround, Senses: LayerEnemy | LayerBullet | LayerToken | LayerHazard},
+	LayerEnemy:  {Blocks: LayerGround, Senses: LayerPlayer | LayerBullet},
+	LayerCorpse: {Blocks: LayerGround},
+	LayerBullet: {Blocks: LayerGround, Senses: LayerPlayer | LayerEnemy},
+	LayerToken:  {Blocks: LayerGround, Senses: LayerPlayer},
+	LayerHazard: {Senses: LayerPlayer | LayerEnemy | LayerBullet | LayerToken},
+}
+
+// layerNames are how layers are written in data files such as prefabs
+var layerNames = map[string]components.CollisionLayer{
+	"ground": LayerGround,
+	"player": LayerPlayer,
+	"enemy":  LayerEnemy,
+	"corpse": LayerCorpse,
+	"bullet": LayerBullet,
+	"token":  LayerToken,
+	"hazard": LayerHazard,
+}
+
+func CollisionRuleFor(layer components.CollisionLayer) CollisionRule {
+	return collisionRules[layer]
+}
+
+// SetCollisionLayer puts col on layer with that layer's rules
+func SetCollisionLayer(col *components.CollisionComponent, layer components.CollisionLayer) {
+	rule := CollisionRuleFor(layer)
+	col.CollisionLayer = layer
+	col.Blocks = rule.Blocks
+	col.Senses = rule.Senses
+}
+
+func CreateCollisionComponent(layer components.CollisionLayer) *components.CollisionComponent {
+	result := &components.CollisionComponent{
+		Active: true,
+	}
+	SetCollisionLayer(result, layer)
+
+	return result
+}
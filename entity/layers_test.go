@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollisionRules(t *testing.T) {
+	t.Parallel()
+
+	for name, layer := range layerNames {
+		_, ok := collisionRules[layer]
+		assert.Truef(t, ok, "%s has no rule", name)
+	}
+
+	notices := func(a, b string) bool {
+		return CreateCollisionComponent(layerNames[a]).Notices(layerNames[b])
+	}
+
+	assert.True(t, CollisionRuleFor(LayerEnemy).Blocks.Has(LayerGround), "enemies stand on buildings")
+	assert.True(t, notices("enemy", "player"))
+	assert.True(t, notices("bullet", "enemy"))
+	assert.False(t, notices("bullet", "token"), "bullets pass through tokens")
+	assert.False(t, notices("token", "bullet"))
+	assert.False(t, notices("player", "corpse"), "the player passes through corpses")
+	assert.False(t, CollisionRuleFor(LayerPlayer).Blocks.Has(LayerCorpse))
+	assert.True(t, notices("hazard", "player"))
+	assert.False(t, notices("ground", "player"), "buildings don't care what's on them")
+
+	col := CreateCollisionComponent(LayerBullet)
+	assert.True(t, col.Active)
+	assert.Equal(t, LayerBullet, col.CollisionLayer)
+	assert.Equal(t, CollisionRuleFor(LayerBullet).Senses, col.Senses)
+
+	assert.Zero(t, CollisionRuleFor(0), "no layer collides with nothing")
+}
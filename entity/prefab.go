@@ -0,0 +1,418 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/EngoEngine/ecs"
+	"github.com/sardap/walk-good-maybe-hd/assets"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/math"
+)
+
+// prefabKinds are the entity types a prefab can be, each one returns an
+// entity with all of it's components but none of the values filled in
+var prefabKinds = map[string]func() ecs.Identifier{
+	"biscuitEnemy":          func() ecs.Identifier { return newBiscuitEnemy() },
+	"ufoBiscuitEnemy":       func() ecs.Identifier { return newUfoBiscuitEnemy() },
+	"token":                 func() ecs.Identifier { return newToken() },
+	"bullet":                func() ecs.Identifier { return newBullet() },
+	"singleScrollableAnime": func() ecs.Identifier { return newSingleScrollableAnime() },
+	"corpse":                func() ecs.Identifier { return newCorpse() },
+	"speedLine":             func() ecs.Identifier { return newSpeedLine() },
+}
+
+// prefabFloat lets whole numbers be written without a decimal point
+type prefabFloat float64
+
+func (f *prefabFloat) UnmarshalTOML(value interface{}) error {
+	switch v := value.(type) {
+	case int64:
+		*f = prefabFloat(v)
+	case float64:
+		*f = prefabFloat(v)
+	default:
+		return fmt.Errorf("expected a number got %v", value)
+	}
+
+	return nil
+}
+
+type prefabVector struct {
+	X prefabFloat `toml:"x"`
+	Y prefabFloat `toml:"y"`
+}
+
+func (v prefabVector) vector() math.Vector2 {
+	return math.Vector2{X: float64(v.X), Y: float64(v.Y)}
+}
+
+type prefabDuration time.Duration
+
+func (d *prefabDuration) UnmarshalText(text []byte) error {
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = prefabDuration(value)
+	return nil
+}
+
+// prefabColor is written as #rrggbb or #rrggbbaa
+type prefabColor color.RGBA
+
+func (c *prefabColor) UnmarshalText(text []byte) error {
+	raw, err := hex.DecodeString(string(bytes.TrimPrefix(text, []byte("#"))))
+	if err != nil || (len(raw) != 3 && len(raw) != 4) {
+		return fmt.Errorf("invalid colour %q", text)
+	}
+
+	*c = prefabColor{R: raw[0], G: raw[1], B: raw[2], A: 255}
+	if len(raw) == 4 {
+		c.A = raw[3]
+	}
+
+	return nil
+}
+
+type PrefabSpawn struct {
+	// Chance of it being picked for a building when it's turn comes up
+	Chance prefabFloat `toml:"chance"`
+	// How far above the building it starts in multiples of it's own height
+	Height prefabFloat `toml:"height"`
+}
+
+type Prefab struct {
+	Name  string `toml:"name"`
+	Kind  string `toml:"kind"`
+	Image string `toml:"image"`
+	// Recolours the image e.g. to tell enemy bullets apart
+	ColorSwap []struct {
+		From prefabColor `toml:"from"`
+		To   prefabColor `toml:"to"`
+	} `toml:"colorSwap"`
+	Tags []string `toml:"tags"`
+	// Nil for things which aren't spawned on buildings
+	Spawn *PrefabSpawn `toml:"spawn"`
+
+	// Component values, nil leaves the component at it's zero value
+	Anime *struct {
+		FrameDuration prefabDuration `toml:"frameDuration"`
+	} `toml:"anime"`
+	Life *struct {
+		HP                prefabFloat    `toml:"hp"`
+		InvincibilityTime prefabDuration `toml:"invincibilityTime"`
+	} `toml:"life"`
+	Damage *struct {
+		BaseDamage prefabFloat `toml:"baseDamage"`
+	} `toml:"damage"`
+	Scrollable *struct {
+		Modifier prefabFloat `toml:"modifier"`
+	} `toml:"scrollable"`
+	Collision *struct {
+		// Layer is a name from layerNames, empty keeps the kind's layer
+		Layer string `toml:"layer"`
+		Swept bool   `toml:"swept"`
+	} `toml:"collision"`
+	BiscuitEnemy *struct {
+		Speed prefabVector `toml:"speed"`
+	} `toml:"biscuitEnemy"`
+	UfoBiscuitEnemy *struct {
+		ShootTime prefabDuration `toml:"shootTime"`
+	} `toml:"ufoBiscuitEnemy"`
+	DestoryOnAnime *struct {
+		Cycles int `toml:"cycles"`
+	} `toml:"destoryOnAnime"`
+}
+
+// Prefabs are kept in the order they were written so spawning is the same
+// every time for a given seed
+type Prefabs struct {
+	list   []*Prefab
+	byName map[string]*Prefab
+}
+
+func LoadPrefabs(data []byte) (*Prefabs, error) {
+	var file struct {
+		Prefabs []*Prefab
+	}
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return nil, err
+	}
+
+	result := &Prefabs{
+		byName: make(map[string]*Prefab),
+	}
+	for i, prefab := range file.Prefabs {
+		if prefab.Name == "" {
+			return nil, fmt.Errorf("prefab %d has no name", i)
+		}
+
+		if _, ok := result.byName[prefab.Name]; ok {
+			return nil, fmt.Errorf("prefab %s is defined twice", prefab.Name)
+		}
+
+		if err := prefab.validate(); err != nil {
+			return nil, fmt.Errorf("prefab %s: %w", prefab.Name, err)
+		}
+
+		result.list = append(result.list, prefab)
+		result.byName[prefab.Name] = prefab
+	}
+
+	return result, nil
+}
+
+var (
+	defaultPrefabs     *Prefabs
+	defaultPrefabsOnce sync.Once
+)
+
+// DefaultPrefabs are the ones built into the game from assets/prefabs.toml
+func DefaultPrefabs() *Prefabs {
+	defaultPrefabsOnce.Do(func() {
+		var err error
+		defaultPrefabs, err = LoadPrefabs(assets.Prefabs)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	return defaultPrefabs
+}
+
+func createPrefab(name string) ecs.Identifier {
+	ent, err := DefaultPrefabs().Create(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return ent
+}
+
+func (p *Prefab) validate() error {
+	create, ok := prefabKinds[p.Kind]
+	if !ok {
+		return fmt.Errorf("unknown kind %q", p.Kind)
+	}
+	ent := create()
+
+	_, hasImage := ent.(components.ImageFace)
+	_, hasTileImage := ent.(components.TileImageFace)
+	if hasImage || hasTileImage {
+		if _, ok := assets.Images[p.Image]; !ok {
+			return fmt.Errorf("unknown image %q", p.Image)
+		}
+	} else if p.Image != "" {
+		return fmt.Errorf("%s has no image", p.Kind)
+	}
+
+	if len(p.Tags) > 0 {
+		if _, ok := ent.(components.IdentityFace); !ok {
+			return fmt.Errorf("%s can't have tags", p.Kind)
+		}
+	}
+	for _, tag := range p.Tags {
+		if _, ok := tagNames[tag]; !ok {
+			return fmt.Errorf("unknown tag %q", tag)
+		}
+	}
+
+	if p.Spawn != nil && (p.Spawn.Chance < 0 || p.Spawn.Chance > 1) {
+		return fmt.Errorf("spawn chance must be between 0 and 1 got %v", p.Spawn.Chance)
+	}
+
+	has := func(set bool, table string, ok bool) error {
+		if set && !ok {
+			return fmt.Errorf("%s has no %s component", p.Kind, table)
+		}
+		return nil
+	}
+
+	_, ok = ent.(components.AnimeFace)
+	if err := has(p.Anime != nil, "anime", ok); err != nil {
+		return err
+	}
+
+	_, ok = ent.(components.LifeFace)
+	if err := has(p.Life != nil, "life", ok); err != nil {
+		return err
+	}
+
+	_, ok = ent.(components.DamageFace)
+	if err := has(p.Damage != nil, "damage", ok); err != nil {
+		return err
+	}
+
+	_, ok = ent.(components.ScrollableFace)
+	if err := has(p.Scrollable != nil, "scrollable", ok); err != nil {
+		return err
+	}
+
+	_, ok = ent.(components.CollisionFace)
+	if err := has(p.Collision != nil, "collision", ok); err != nil {
+		return err
+	}
+	if p.Collision != nil && p.Collision.Layer != "" {
+		if _, ok := layerNames[p.Collision.Layer]; !ok {
+			return fmt.Errorf("unknown layer %q", p.Collision.Layer)
+		}
+	}
+
+	_, ok = ent.(components.BiscuitEnemyFace)
+	if err := has(p.BiscuitEnemy != nil, "biscuitEnemy", ok); err != nil {
+		return err
+	}
+
+	_, ok = ent.(components.UfoBiscuitEnemyFace)
+	if err := has(p.UfoBiscuitEnemy != nil, "ufoBiscuitEnemy", ok); err != nil {
+		return err
+	}
+
+	_, ok = ent.(components.DestoryOnAnimeFace)
+	return has(p.DestoryOnAnime != nil, "destoryOnAnime", ok)
+}
+
+func (p *Prefab) create() ecs.Identifier {
+	ent := prefabKinds[p.Kind]()
+
+	if p.Image != "" {
+		asset := assets.Images[p.Image]
+
+		var clrMap map[color.RGBA]color.RGBA
+		if len(p.ColorSwap) > 0 {
+			clrMap = make(map[color.RGBA]color.RGBA)
+			for _, swap := range p.ColorSwap {
+				clrMap[color.RGBA(swap.From)] = color.RGBA(swap.To)
+			}
+		}
+		img, _ := assets.LoadEbitenImageColorSwap(asset, clrMap)
+
+		size := math.Vector2{
+			X: float64(img.Bounds().Dx()),
+			Y: float64(img.Bounds().Dy()),
+		}
+
+		if tileImage, ok := ent.(components.TileImageFace); ok {
+			frameWidth := assets.FrameWidth(asset)
+			if frameWidth == 0 {
+				frameWidth = img.Bounds().Dx()
+			}
+
+			tileMap := components.CreateTileMap(1, 1, img, frameWidth)
+			tileMap.SetTile(0, 0, 0)
+			tileImage.GetTileImageComponent().TileMap = tileMap
+			size.X = float64(frameWidth)
+		} else if image, ok := ent.(components.ImageFace); ok {
+			image.GetImageComponent().Image = img
+		}
+
+		ent.(components.TransformFace).GetTransformComponent().Size = size
+
+		if col, ok := ent.(components.CollisionFace); ok {
+			col.GetCollisionComponent().Hitbox = DefaultHitboxes().For(p.Image)
+		}
+	}
+
+	if identity, ok := ent.(components.IdentityFace); ok {
+		tags := []int{}
+		for _, tag := range p.Tags {
+			tags = append(tags, tagNames[tag])
+		}
+		identity.GetIdentityComponent().Tags = tags
+	}
+
+	if p.Anime != nil {
+		anime := ent.(components.AnimeFace).GetAnimeComponent()
+		anime.FrameDuration = time.Duration(p.Anime.FrameDuration)
+		anime.FrameRemaining = anime.FrameDuration
+	}
+
+	if p.Life != nil {
+		life := ent.(components.LifeFace).GetLifeComponent()
+		life.HP = float64(p.Life.HP)
+		life.InvincibilityTime = time.Duration(p.Life.InvincibilityTime)
+	}
+
+	if p.Damage != nil {
+		ent.(components.DamageFace).GetDamageComponent().BaseDamage = float64(p.Damage.BaseDamage)
+	}
+
+	if p.Scrollable != nil {
+		ent.(components.ScrollableFace).GetScrollableComponent().Modifier = float64(p.Scrollable.Modifier)
+	}
+
+	if p.Collision != nil {
+		col := ent.(components.CollisionFace).GetCollisionComponent()
+		if p.Collision.Layer != "" {
+			SetCollisionLayer(col, layerNames[p.Collision.Layer])
+		}
+		col.Swept = p.Collision.Swept
+	}
+
+	if p.BiscuitEnemy != nil {
+		ent.(components.BiscuitEnemyFace).GetBiscuitEnemyComponent().Speed = p.BiscuitEnemy.Speed.vector()
+	}
+
+	if p.UfoBiscuitEnemy != nil {
+		ufo := ent.(components.UfoBiscuitEnemyFace).GetUfoBiscuitEnemyComponent()
+		ufo.ShootTime = time.Duration(p.UfoBiscuitEnemy.ShootTime)
+		ufo.ShootTimeRemaning = ufo.ShootTime
+	}
+
+	if p.DestoryOnAnime != nil {
+		ent.(components.DestoryOnAnimeFace).GetDestoryOnAnimeComponent().CyclesTilDeath = p.DestoryOnAnime.Cycles
+	}
+
+	return ent
+}
+
+func (p *Prefabs) Get(name string) (*Prefab, bool) {
+	prefab, ok := p.byName[name]
+	return prefab, ok
+}
+
+// Spawnable are the prefabs which can be put on buildings in the order they
+// should be tried
+func (p *Prefabs) Spawnable() []*Prefab {
+	var result []*Prefab
+	for _, prefab := range p.list {
+		if prefab.Spawn != nil {
+			result = append(result, prefab)
+		}
+	}
+
+	return result
+}
+
+// Create builds the entity without adding it to a world
+func (p *Prefabs) Create(name string) (ecs.Identifier, error) {
+	prefab, ok := p.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown prefab %q", name)
+	}
+
+	return prefab.create(), nil
+}
+
+// Instantiate builds the entity and adds it to the world, setup is called
+// before it's added so it can be positioned first
+func (p *Prefabs) Instantiate(w *ecs.World, name string, setup func(ecs.Identifier)) (ecs.Identifier, error) {
+	ent, err := p.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if setup != nil {
+		setup(ent)
+	}
+	w.AddEntity(ent)
+
+	return ent, nil
+}
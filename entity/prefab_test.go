@@ -0,0 +1,192 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPrefabs(t *testing.T) {
+	t.Parallel()
+
+	prefabs := DefaultPrefabs()
+
+	var names []string
+	for _, prefab := range prefabs.Spawnable() {
+		names = append(names, prefab.Name)
+	}
+	assert.Equal(t, []string{"biscuitEnemy", "ufoBiscuitEnemy", "jumpUpToken", "speedUpToken"}, names)
+
+	ufo, ok := prefabs.Get("ufoBiscuitEnemy")
+	assert.True(t, ok)
+	assert.Equal(t, prefabFloat(2.5), ufo.Spawn.Height)
+	assert.Equal(t, time.Second, time.Duration(ufo.UfoBiscuitEnemy.ShootTime))
+
+	_, err := prefabs.Create("nothing")
+	assert.Error(t, err)
+
+	biscuit := CreateBiscuitEnemy()
+	assert.Equal(t, float64(100), biscuit.HP)
+	assert.Equal(t, []int{TagEnemy}, biscuit.Tags)
+	assert.Equal(t, 200*time.Millisecond, biscuit.FrameRemaining)
+	assert.Equal(t, float64(biscuit.TileMap.TileWidth), biscuit.Size.X)
+	assert.NotEqual(t, biscuit.ID(), CreateBiscuitEnemy().ID(), "every entity should be new")
+	assert.True(t, biscuit.Swept)
+	assert.Equal(t, DefaultHitboxes().For("biscuitEnemyIdle"), biscuit.Hitbox, "hitbox should come from the image")
+	assert.True(t, CreatePlayerBullet().Swept, "bullets are too fast to check where they end up")
+
+	for _, corpse := range []*Corpse{CreateBiscuitEnemyDeath(), CreateUfoBiscuitEnemyDeath()} {
+		assert.Equal(t, LayerCorpse, corpse.CollisionLayer, "dead enemies shouldn't get in the player's way")
+		assert.Equal(t, 1, corpse.CyclesTilDeath)
+	}
+
+	token := CreateSpeedUpToken()
+	assert.Equal(t, []int{TagSpeedToken}, token.Tags)
+	assert.False(t, token.Swept)
+	assert.Nil(t, token.Hitbox, "tokens don't have a hitbox")
+	assert.Equal(t, float64(token.Image.Bounds().Dx()), token.TransformComponent.Size.X)
+
+	enemyBullet, _ := prefabs.Get("enemyBullet")
+	assert.Len(t, enemyBullet.ColorSwap, 3, "enemy bullets are recoloured")
+}
+
+func TestLoadPrefabs(t *testing.T) {
+	t.Parallel()
+
+	prefabs, err := LoadPrefabs([]byte(`
+[[Prefabs]]
+name="fastBiscuit"
+kind="biscuitEnemy"
+image="biscuitEnemyIdle"
+tags=["enemy", "ufo"]
+
+[[Prefabs.colorSwap]]
+from="#4bcd4b"
+to="#cd4b4bf0"
+
+[Prefabs.spawn]
+chance=0.25
+height=3
+
+[Prefabs.anime]
+frameDuration="50ms"
+
+[Prefabs.life]
+hp=20
+invincibilityTime="1.5s"
+
+[Prefabs.biscuitEnemy]
+speed={x=300, y=10}
+
+[[Prefabs]]
+name="deadBiscuit"
+kind="biscuitEnemy"
+image="biscuitEnemyIdle"
+
+[Prefabs.collision]
+layer="corpse"
+`))
+	assert.NoError(t, err)
+
+	prefab, ok := prefabs.Get("fastBiscuit")
+	assert.True(t, ok)
+	assert.Equal(t, prefabFloat(0.25), prefab.Spawn.Chance)
+	assert.Equal(t, 50*time.Millisecond, time.Duration(prefab.Anime.FrameDuration))
+	assert.Equal(t, 1500*time.Millisecond, time.Duration(prefab.Life.InvincibilityTime))
+	assert.Equal(t, prefabFloat(300), prefab.BiscuitEnemy.Speed.X)
+	assert.Equal(t, prefabColor{R: 0xcd, G: 0x4b, B: 0x4b, A: 0xf0}, prefab.ColorSwap[0].To)
+	ent, _ := prefabs.Create("deadBiscuit")
+	assert.Equal(t, LayerCorpse, ent.(*BiscuitEnemy).CollisionLayer)
+	assert.Nil(t, prefab.Damage)
+	assert.Len(t, prefabs.Spawnable(), 1)
+
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{name: "no name", data: `[[Prefabs]]
+kind="token"
+image="tokenJumpUp"`},
+		{name: "unknown kind", data: `[[Prefabs]]
+name="a"
+kind="dragon"`},
+		{name: "unknown image", data: `[[Prefabs]]
+name="a"
+kind="token"
+image="dragon"`},
+		{name: "missing image", data: `[[Prefabs]]
+name="a"
+kind="token"`},
+		{name: "unknown tag", data: `[[Prefabs]]
+name="a"
+kind="token"
+image="tokenJumpUp"
+tags=["dragon"]`},
+		{name: "missing component", data: `[[Prefabs]]
+name="a"
+kind="token"
+image="tokenJumpUp"
+[Prefabs.anime]
+frameDuration="1s"`},
+		{name: "bad duration", data: `[[Prefabs]]
+name="a"
+kind="biscuitEnemy"
+image="biscuitEnemyIdle"
+[Prefabs.anime]
+frameDuration="soon"`},
+		{name: "bad colour", data: `[[Prefabs]]
+name="a"
+kind="bullet"
+image="bulletSmallGreen"
+[[Prefabs.colorSwap]]
+from="#4bcd"
+to="#cd4b4b"`},
+		{name: "unknown layer", data: `[[Prefabs]]
+name="a"
+kind="token"
+image="tokenJumpUp"
+[Prefabs.collision]
+layer="dragon"`},
+		{name: "bad chance", data: `[[Prefabs]]
+name="a"
+kind="token"
+image="tokenJumpUp"
+[Prefabs.spawn]
+chance=2`},
+		{name: "twice", data: `[[Prefabs]]
+name="a"
+kind="token"
+image="tokenJumpUp"
+[[Prefabs]]
+name="a"
+kind="token"
+image="tokenJumpUp"`},
+	}
+
+	for _, testCase := range testCases {
+		_, err := LoadPrefabs([]byte(testCase.data))
+		assert.Errorf(t, err, testCase.name)
+	}
+}
+
+func TestPrefabKinds(t *testing.T) {
+	t.Parallel()
+
+	// Every component a kind is made with has to exist otherwise systems
+	// would get nil components
+	for kind, create := range prefabKinds {
+		ent := create()
+		_, ok := ent.(components.TransformFace)
+		assert.Truef(t, ok, "%s needs a transform", kind)
+
+		value := reflect.ValueOf(ent).Elem()
+		for i := 0; i < value.NumField(); i++ {
+			if value.Field(i).Kind() == reflect.Ptr {
+				assert.Falsef(t, value.Field(i).IsNil(), "%s %s", kind, value.Type().Field(i).Name)
+			}
+		}
+	}
+}
@@ -1,11 +1,40 @@
-package entity
-
-const (
-	TagGround int = iota
-	TagPlayer
-	TagBullet
-	TagEnemy
-	TagUfo
-	TagJumpToken
-	TagSpeedToken
-)
+package entity
+
+import "strconv"
+
+const (
+	TagGround int = iota
+	TagPlayer
+	TagBullet
+	TagEnemy
+	TagUfo
+	TagJumpToken
+	TagSpeedToken
+)
+
+// tagNames are how tags are written in data files such as prefabs
+var tagNames = map[string]int{
+	"ground":     TagGround,
+	"player":     TagPlayer,
+	"bullet":     TagBullet,
+	"enemy":      TagEnemy,
+	"ufo":        TagUfo,
+	"jumpToken":  TagJumpToken,
+	"speedToken": TagSpeedToken,
+}
+
+func TagByName(name string) (int, bool) {
+	tag, ok := tagNames[name]
+	return tag, ok
+}
+
+// TagName is the name used in data files or the number if it doesn't have one
+func TagName(tag int) string {
+	for name, value := range tagNames {
+		if value == tag {
+			return name
+		}
+	}
+
+	return strconv.Itoa(tag)
+}
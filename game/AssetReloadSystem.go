@@ -0,0 +1,101 @@
+package game
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sardap/walk-good-maybe-hd/assets"
+	"github.com/sardap/walk-good-maybe-hd/components"
+)
+
+type ImageReloadable interface {
+	ecs.BasicFace
+	components.ImageFace
+}
+
+type TileImageReloadable interface {
+	ecs.BasicFace
+	components.TileImageFace
+}
+
+// AssetReloadSystem swaps images changed on disk into whatever is drawing them,
+// only dev builds ever reload anything
+type AssetReloadSystem struct {
+	systemOrder
+	imageEnts     map[uint64]ImageReloadable
+	tileImageEnts map[uint64]TileImageReloadable
+	watcher       *assets.ReloadWatcher
+}
+
+func CreateAssetReloadSystem() *AssetReloadSystem {
+	return &AssetReloadSystem{}
+}
+
+func (s *AssetReloadSystem) runsBefore() []ecs.System {
+	return []ecs.System{(*TileImageRenderSystem)(nil), (*ImageRenderSystem)(nil)}
+}
+
+func (s *AssetReloadSystem) New(world *ecs.World) {
+	s.imageEnts = make(map[uint64]ImageReloadable)
+	s.tileImageEnts = make(map[uint64]TileImageReloadable)
+	// Anything reloaded before the scene started is loaded fresh already
+	s.watcher = assets.WatchReloads()
+}
+
+func (s *AssetReloadSystem) end() {
+	s.watcher.Stop()
+}
+
+func (s *AssetReloadSystem) Update(_ float32) {
+	if !assets.DevBuild {
+		return
+	}
+
+	s.swap(s.watcher.Reloads())
+}
+
+func (s *AssetReloadSystem) swap(reloads []assets.ReloadedImage) {
+	if len(reloads) == 0 {
+		return
+	}
+
+	// In order since an image can be reloaded again before it's swapped in
+	replace := func(img *ebiten.Image) *ebiten.Image {
+		for _, reload := range reloads {
+			if img == reload.Old {
+				img = reload.New
+			}
+		}
+		return img
+	}
+
+	for _, ent := range s.imageEnts {
+		imageCom := ent.GetImageComponent()
+		imageCom.Image = replace(imageCom.Image)
+	}
+
+	for _, ent := range s.tileImageEnts {
+		tileMap := ent.GetTileImageComponent().TileMap
+		if tileMap != nil {
+			tileMap.TilesImg = replace(tileMap.TilesImg)
+		}
+	}
+}
+
+func (s *AssetReloadSystem) Add(r ecs.Identifier) {
+	if reloadable, ok := r.(ImageReloadable); ok {
+		s.imageEnts[reloadable.GetBasicEntity().ID()] = reloadable
+	}
+
+	if reloadable, ok := r.(TileImageReloadable); ok {
+		s.tileImageEnts[reloadable.GetBasicEntity().ID()] = reloadable
+	}
+}
+
+func (s *AssetReloadSystem) Remove(e ecs.BasicEntity) {
+	delete(s.imageEnts, e.ID())
+	delete(s.tileImageEnts, e.ID())
+}
+
+func (s *AssetReloadSystem) AddByInterface(o ecs.Identifier) {
+	s.Add(o)
+}
@@ -0,0 +1,195 @@
+package game
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/sardap/walk-good-maybe-hd/assets"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+)
+
+// EffectSystem plays the sounds and spawns the effects for gameplay events so
+// the systems publishing them don't need to know about any of it
+type EffectSystem struct {
+	systemOrder
+	mainGameScene    *MainGameScene
+	world            *ecs.World
+	freePlayerPool   []*entity.SoundPlayer
+	activePlayerPool []*entity.SoundPlayer
+}
+
+func CreateEffectSystem(mainGameScene *MainGameScene) *EffectSystem {
+	return &EffectSystem{
+		mainGameScene: mainGameScene,
+	}
+}
+
+func (s *EffectSystem) New(world *ecs.World) {
+	s.world = world
+	s.freePlayerPool = nil
+	s.activePlayerPool = nil
+
+	events := worldEvents(world)
+	if events == nil {
+		return
+	}
+
+	events.Subscribe(EventKindTokenCollected, func(e Event) {
+		s.onTokenCollected(e.(TokenCollected))
+	})
+	events.Subscribe(EventKindEntityDamaged, func(e Event) {
+		s.onDamaged(e.(EntityDamaged))
+	})
+	events.Subscribe(EventKindEntityDied, func(e Event) {
+		s.onDied(e.(EntityDied))
+	})
+	events.Subscribe(EventKindBulletFired, func(e Event) {
+		s.onBulletFired(e.(BulletFired))
+	})
+}
+
+func (s *EffectSystem) getPlayer() *entity.SoundPlayer {
+	if len(s.freePlayerPool) > 0 {
+		result := s.freePlayerPool[len(s.freePlayerPool)-1]
+		s.freePlayerPool = s.freePlayerPool[:len(s.freePlayerPool)-1]
+		s.activePlayerPool = append(s.activePlayerPool, result)
+		return result
+	}
+
+	return nil
+}
+
+func (s *EffectSystem) freePlayer(toFree *entity.SoundPlayer) {
+	toFree.Active = false
+	toFree.Restart = false
+	for i, player := range s.activePlayerPool {
+		if player.ID() == toFree.ID() {
+			s.activePlayerPool[i] = s.activePlayerPool[len(s.activePlayerPool)-1]
+			s.activePlayerPool = s.activePlayerPool[:len(s.activePlayerPool)-1]
+			s.freePlayerPool = append(s.freePlayerPool, toFree)
+			break
+		}
+	}
+}
+
+func (s *EffectSystem) playSound(sound components.Sound) {
+	player := s.getPlayer()
+	if player == nil {
+		return
+	}
+
+	player.Sound = sound
+	player.Active = true
+	player.Restart = true
+}
+
+func playSoundOn(ent interface{}, sound components.Sound) {
+	soundFace, ok := ent.(components.SoundFace)
+	if !ok {
+		return
+	}
+
+	soundCom := soundFace.GetSoundComponent()
+	soundCom.Sound = sound
+	soundCom.Active = true
+	soundCom.Restart = true
+}
+
+func (s *EffectSystem) onTokenCollected(e TokenCollected) {
+	switch e.Tag {
+	case entity.TagJumpToken:
+		playSoundOn(e.Player, components.LoadSound(assets.SoundByCollect5))
+
+	case entity.TagSpeedToken:
+		playSoundOn(e.Player, components.LoadSound(assets.SoundJdwBlowOne))
+
+		rand := s.mainGameScene.Rand
+		for i := 0; i < rand.Intn(10)+7; i++ {
+			speedLine := entity.CreateSpeedLine()
+			speedLine.Postion.X = windowWidth + float64(rand.Intn(2000))
+			speedLine.Postion.Y = float64(rand.Intn(windowHeight)) + rand.Float64()
+			speedLine.ImageComponent.Layer = ImageLayerObjects
+			s.world.AddEntity(speedLine)
+		}
+	}
+}
+
+func (s *EffectSystem) onDamaged(e EntityDamaged) {
+	player, ok := e.Ent.(*entity.Player)
+	if !ok {
+		return
+	}
+
+	s.playSound(components.LoadSound(assets.SoundWhaleDamage))
+	player.TileMap.Options.InvertColor = true
+	player.AddTimer(player.InvincibilityTime, func() {
+		player.TileMap.Options.InvertColor = false
+	})
+}
+
+func (s *EffectSystem) onDied(e EntityDied) {
+	trans := e.Ent.GetTransformComponent()
+
+	// Nobody would see or hear it
+	if trans.Postion.X < 0 || trans.Postion.X > windowWidth {
+		return
+	}
+
+	if _, ok := e.Ent.(components.BiscuitEnemyFace); ok {
+		s.playSound(components.LoadSound(assets.SoundPdBiscuitDeath))
+
+		biscuitEnemyDeath := entity.CreateBiscuitEnemyDeath()
+		biscuitEnemyDeath.Postion = trans.Postion
+		biscuitEnemyDeath.Layer = ImageLayerObjects
+		s.world.AddEntity(biscuitEnemyDeath)
+	} else if _, ok := e.Ent.(components.UfoBiscuitEnemyFace); ok {
+		s.playSound(components.LoadSound(assets.SoundUfoBiscuitEnemyDeath))
+
+		ufoDeath := entity.CreateUfoBiscuitEnemyDeath()
+		ufoDeath.Postion = trans.Postion
+		ufoDeath.Layer = ImageLayerObjects
+		s.world.AddEntity(ufoDeath)
+	} else if player, ok := e.Ent.(*entity.Player); ok {
+		s.playSound(components.LoadSound(assets.SoundWhaleDamage))
+		s.world.AddEntity(createPlayerDeath(player))
+	}
+}
+
+// createPlayerDeath hops up from where player was then falls off the bottom
+// of the screen while the scene is dying
+func createPlayerDeath(player *entity.Player) *entity.PlayerDeath {
+	death := entity.CreatePlayerDeath()
+	death.Postion = player.Postion
+	death.Layer = ImageLayerObjects
+	death.TileMap.Options.InvertX = player.TileMap.Options.InvertX
+
+	start := death.Postion.Y
+	fall := windowHeight - start
+	death.AddTween(0, 1, deathFall, func(t float64) {
+		death.Postion.Y = start + fall*t*t - deathHop*4*t*(1-t)
+	})
+
+	return death
+}
+
+func (s *EffectSystem) onBulletFired(e BulletFired) {
+	playSoundOn(e.Shooter, components.LoadSound(assets.SoundByLaserFour))
+}
+
+func (s *EffectSystem) Update(dt float32) {
+	if len(s.freePlayerPool) == 0 && len(s.activePlayerPool) == 0 {
+		for i := 0; i < 10; i++ {
+			player := entity.CreateSoundPlayer(assets.SoundPdBiscuitDeath)
+			s.freePlayerPool = append(s.freePlayerPool, player)
+			s.world.AddEntity(player)
+		}
+	}
+
+	for _, player := range s.activePlayerPool {
+		if player.Player != nil && !player.Player.IsPlaying() {
+			defer s.freePlayer(player)
+		}
+	}
+}
+
+func (s *EffectSystem) Remove(e ecs.BasicEntity) {
+}
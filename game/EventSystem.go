@@ -0,0 +1,32 @@
+package game
+
+import (
+	"github.com/EngoEngine/ecs"
+)
+
+// EventSystem has the lowest priority so events get delivered at the end of
+// every world update once everything else has run
+type EventSystem struct {
+	systemOrder
+	bus *EventBus
+}
+
+func CreateEventSystem(bus *EventBus) *EventSystem {
+	return &EventSystem{
+		bus: bus,
+	}
+}
+
+// Last so events go out at the end of the frame
+func (s *EventSystem) runsLast() {
+}
+
+func (s *EventSystem) New(world *ecs.World) {
+}
+
+func (s *EventSystem) Update(dt float32) {
+	s.bus.Dispatch()
+}
+
+func (s *EventSystem) Remove(e ecs.BasicEntity) {
+}
@@ -0,0 +1,268 @@
+package game
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+	"github.com/sardap/walk-good-maybe-hd/scores"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+	"golang.org/x/image/font"
+)
+
+type gameOverOption int
+
+const (
+	gameOverOptionRetry gameOverOption = iota
+	gameOverOptionNewRun
+	gameOverOptionTitle
+	gameOverOptionLength
+)
+
+func (g gameOverOption) String() string {
+	switch g {
+	case gameOverOptionRetry:
+		return "RETRY"
+	case gameOverOptionNewRun:
+		return "NEW RUN"
+	case gameOverOptionTitle:
+		return "QUIT TO TITLE"
+	}
+
+	panic("Unknown game over option")
+}
+
+// nameEntry is picking a name a letter at a time like an arcade cabinet
+type nameEntry struct {
+	letters []byte
+	pos     int
+}
+
+func createNameEntry() *nameEntry {
+	return &nameEntry{
+		letters: bytes.Repeat([]byte{'A'}, scores.NameLength),
+	}
+}
+
+// change moves the current letter through the alphabet wrapping around
+func (n *nameEntry) change(by int) {
+	n.letters[n.pos] = 'A' + byte(utility.WrapInt(int(n.letters[n.pos]-'A')+by, 0, 26))
+}
+
+// next moves on to the next letter, true once they've all been picked
+func (n *nameEntry) next() bool {
+	n.pos++
+	return n.pos >= len(n.letters)
+}
+
+func (n *nameEntry) name() string {
+	return string(n.letters)
+}
+
+// GameOverScene shows how the run went once the player has died, a run good
+// enough for the high scores gets a name put in first
+type GameOverScene struct {
+	Stats       RunStats
+	world       *ecs.World
+	inputEnt    *entity.InputEnt
+	selectedIdx gameOverOption
+	table       *scores.Table
+	entry       *nameEntry
+	// rank is where the run went in the high scores, -1 if it didn't
+	rank       int
+	message    string
+	titleFont  font.Face
+	statFont   font.Face
+	optionFont font.Face
+}
+
+func (g *GameOverScene) Start(game *Game) {
+	g.world = &ecs.World{}
+
+	var inputable *Inputable
+	AddSystemInterface(g.world, CreateInputSystem(), inputable, nil)
+
+	g.inputEnt = entity.CreateMenuInput()
+	game.applyInputSettings(g.inputEnt.InputComponent)
+	g.world.AddEntity(g.inputEnt)
+
+	g.selectedIdx = gameOverOptionRetry
+	g.rank = -1
+	g.message = ""
+	g.entry = nil
+
+	// Saving over a table that couldn't be read would lose everything in it
+	// so the run just isn't saved
+	table, err := game.Scores().Load()
+	if err != nil {
+		g.message = fmt.Sprintf("UNABLE TO LOAD SCORES NOT SAVING %v", err)
+		g.table = nil
+	} else {
+		g.table = table
+	}
+	if g.table != nil && g.Stats.Score() > 0 && g.table.Qualifies(g.Stats.Score()) {
+		g.entry = createNameEntry()
+	}
+
+	g.titleFont = createFontFace(120, 72)
+	g.statFont = createFontFace(60, 72)
+	g.optionFont = createFontFace(80, 72)
+}
+
+func (g *GameOverScene) End(*Game) {
+	g.world = nil
+	g.inputEnt = nil
+	g.table = nil
+	g.entry = nil
+}
+
+func (g *GameOverScene) Transition() Transition {
+	return Transition{
+		Type:     TransitionFade,
+		Duration: 500 * time.Millisecond,
+		Color:    color.Black,
+	}
+}
+
+func (g *GameOverScene) selected(game *Game, option gameOverOption) {
+	switch option {
+	case gameOverOptionRetry:
+		game.ChangeScene(&MainGameScene{Seed: g.Stats.Seed})
+	case gameOverOptionNewRun:
+		game.ChangeScene(&MainGameScene{})
+	case gameOverOptionTitle:
+		game.ChangeScene(&TitleScene{})
+	}
+}
+
+// saveScore puts the run in the high scores under the name entered
+func (g *GameOverScene) saveScore(game *Game) {
+	if g.table == nil {
+		g.entry = nil
+		return
+	}
+
+	g.rank = g.table.Add(scores.Entry{
+		Name:     g.entry.name(),
+		Score:    g.Stats.Score(),
+		Distance: g.Stats.Distance,
+		Seed:     g.Stats.Seed,
+		Date:     time.Now(),
+	})
+	g.entry = nil
+
+	if err := game.Scores().Save(g.table); err != nil {
+		g.message = fmt.Sprintf("UNABLE TO SAVE SCORE %v", err)
+	}
+}
+
+func (g *GameOverScene) updateEntry(game *Game) {
+	if g.inputEnt.InputJustPressed(components.InputKindSelect) {
+		if g.entry.next() {
+			g.saveScore(game)
+		}
+		return
+	}
+
+	if g.inputEnt.InputJustPressed(components.InputKindMoveUp) {
+		g.entry.change(1)
+	}
+
+	if g.inputEnt.InputJustPressed(components.InputKindMoveDown) {
+		g.entry.change(-1)
+	}
+}
+
+func (g *GameOverScene) Update(dt time.Duration, game *Game) {
+	updateWorld(g.world, float32(dt)/float32(time.Second))
+
+	if g.entry != nil {
+		g.updateEntry(game)
+		return
+	}
+
+	if g.inputEnt.InputJustPressed(components.InputKindSelect) {
+		defer g.selected(game, g.selectedIdx)
+		return
+	}
+
+	if g.inputEnt.InputJustPressed(components.InputKindMoveUp) {
+		g.selectedIdx = gameOverOption(utility.WrapInt(int(g.selectedIdx)-1, 0, int(gameOverOptionLength)))
+	}
+
+	if g.inputEnt.InputJustPressed(components.InputKindMoveDown) {
+		g.selectedIdx = gameOverOption(utility.WrapInt(int(g.selectedIdx)+1, 0, int(gameOverOptionLength)))
+	}
+}
+
+// statLines are the stats as they're shown
+func (g *GameOverScene) statLines() []string {
+	return []string{
+		fmt.Sprintf("SCORE %d", g.Stats.Score()),
+		fmt.Sprintf("DISTANCE %dM", int(g.Stats.Distance)),
+		fmt.Sprintf("KILLS %d", g.Stats.Kills),
+		fmt.Sprintf("TOKENS %d", g.Stats.Tokens),
+		fmt.Sprintf("TIME %s", g.Stats.Time.Truncate(time.Second)),
+		fmt.Sprintf("SEED %s", utility.EncodeSeed(g.Stats.Seed)),
+	}
+}
+
+func (g *GameOverScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.Black)
+
+	drawCentered := func(str string, face font.Face, y int, clr color.Color) {
+		b := text.BoundString(face, str)
+		text.Draw(screen, str, face, windowWidth/2-b.Dx()/2, y, clr)
+	}
+
+	drawCentered("GAME OVER", g.titleFont, 300, color.RGBA{R: 255, A: 255})
+
+	y := 450
+	for _, line := range g.statLines() {
+		drawCentered(line, g.statFont, y, color.White)
+		y += 100
+	}
+
+	if g.message != "" {
+		drawCentered(g.message, g.statFont, 1020, color.RGBA{R: 255, A: 255})
+	}
+
+	if g.entry != nil {
+		drawCentered("NEW HIGH SCORE", g.optionFont, 1150, color.White)
+
+		// Each letter is drawn on its own so the one being picked can be red
+		const letterWidth = 120
+		x := windowWidth/2 - letterWidth*len(g.entry.letters)/2
+		for i, letter := range g.entry.letters {
+			clr := color.Color(color.White)
+			if i == g.entry.pos {
+				clr = color.RGBA{R: 255, A: 255}
+			}
+
+			text.Draw(screen, string(letter), g.titleFont, x, 1350, clr)
+			x += letterWidth
+		}
+		return
+	}
+
+	if g.rank >= 0 {
+		drawCentered(fmt.Sprintf("HIGH SCORE #%d", g.rank+1), g.statFont, 1080, color.White)
+	}
+
+	y = 1200
+	for option := gameOverOption(0); option < gameOverOptionLength; option++ {
+		clr := color.Color(color.White)
+		if option == g.selectedIdx {
+			clr = color.RGBA{R: 255, A: 255}
+		}
+
+		drawCentered(option.String(), g.optionFont, y, clr)
+		y += 130
+	}
+}
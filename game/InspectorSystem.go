@@ -0,0 +1,415 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+	"golang.org/x/image/font"
+)
+
+const (
+	inspectorFloatStep    = 1
+	inspectorDurationStep = 100 * time.Millisecond
+	inspectorMaxLines     = 40
+	inspectorLineHeight   = 36
+	inspectorPanelWidth   = 1000
+	// How far into structs like Vector2 fields are shown
+	inspectorMaxDepth = 2
+)
+
+type Inspectable interface {
+	ecs.BasicFace
+	components.TransformFace
+}
+
+// InspectorSystem shows every component on an entity and lets numbers be
+// nudged while the game is running
+type InspectorSystem struct {
+	systemOrder
+	ents    map[uint64]Inspectable
+	input   *entity.InputEnt
+	Enabled bool
+	// Selected is the ID of the entity being shown, 0 for nothing
+	Selected uint64
+	FieldIdx int
+	overlay  *ebiten.Image
+	font     font.Face
+}
+
+func CreateInspectorSystem(input *entity.InputEnt) *InspectorSystem {
+	return &InspectorSystem{
+		input: input,
+	}
+}
+
+func (s *InspectorSystem) runsAfter() []ecs.System {
+	return []ecs.System{(*InputSystem)(nil)}
+}
+
+func (s *InspectorSystem) New(world *ecs.World) {
+	s.ents = make(map[uint64]Inspectable)
+	s.overlay = ebiten.NewImage(windowWidth, windowHeight)
+}
+
+func (s *InspectorSystem) Update(dt float32) {
+	if s.input == nil {
+		return
+	}
+
+	if s.input.InputJustPressed(components.InputKindToggleInspector) {
+		s.Enabled = !s.Enabled
+	}
+
+	if !s.Enabled {
+		return
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		s.SelectAt(float64(x), float64(y))
+	}
+
+	if s.input.InputJustPressed(components.InputKindInspectorNext) {
+		s.Cycle(1)
+	}
+
+	if s.input.InputJustPressed(components.InputKindInspectorPrev) {
+		s.Cycle(-1)
+	}
+
+	if fields := len(s.Fields()); fields > 0 {
+		if s.input.InputJustPressed(components.InputKindInspectorFieldNext) {
+			s.FieldIdx = utility.WrapInt(s.FieldIdx+1, 0, fields)
+		}
+
+		if s.input.InputJustPressed(components.InputKindInspectorFieldPrev) {
+			s.FieldIdx = utility.WrapInt(s.FieldIdx-1, 0, fields)
+		}
+	}
+
+	if s.input.InputJustPressed(components.InputKindInspectorIncrease) {
+		s.Nudge(1)
+	}
+
+	if s.input.InputJustPressed(components.InputKindInspectorDecrease) {
+		s.Nudge(-1)
+	}
+}
+
+func (s *InspectorSystem) sortedIDs() []uint64 {
+	ids := make([]uint64, 0, len(s.ents))
+	for id := range s.ents {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func (s *InspectorSystem) Select(id uint64) bool {
+	if _, ok := s.ents[id]; !ok {
+		return false
+	}
+
+	s.Selected = id
+	s.FieldIdx = 0
+	return true
+}
+
+// Cycle moves through the entities in the order they were made
+func (s *InspectorSystem) Cycle(dir int) {
+	ids := s.sortedIDs()
+	if len(ids) == 0 {
+		return
+	}
+
+	idx := sort.Search(len(ids), func(i int) bool { return ids[i] >= s.Selected })
+	if idx < len(ids) && ids[idx] == s.Selected {
+		idx = utility.WrapInt(idx+dir, 0, len(ids))
+	} else if dir < 0 {
+		idx = utility.WrapInt(idx-1, 0, len(ids))
+	} else {
+		idx = utility.WrapInt(idx, 0, len(ids))
+	}
+
+	s.Select(ids[idx])
+}
+
+// SelectAt picks the smallest entity under the point so things in front of
+// the backgrounds can be clicked
+func (s *InspectorSystem) SelectAt(x, y float64) bool {
+	var best uint64
+	bestArea := -1.0
+	for _, id := range s.sortedIDs() {
+		trans := s.ents[id].GetTransformComponent()
+		if x < trans.Postion.X || x > trans.Postion.X+trans.Size.X ||
+			y < trans.Postion.Y || y > trans.Postion.Y+trans.Size.Y {
+			continue
+		}
+
+		area := trans.Size.X * trans.Size.Y
+		if bestArea < 0 || area < bestArea {
+			best = id
+			bestArea = area
+		}
+	}
+
+	if bestArea < 0 {
+		return false
+	}
+
+	return s.Select(best)
+}
+
+func (s *InspectorSystem) SelectedEnt() Inspectable {
+	return s.ents[s.Selected]
+}
+
+func (s *InspectorSystem) Fields() []InspectorField {
+	ent := s.SelectedEnt()
+	if ent == nil {
+		return nil
+	}
+
+	return inspectorFields(ent)
+}
+
+// Nudge changes the selected field by one step in dir
+func (s *InspectorSystem) Nudge(dir int) bool {
+	fields := s.Fields()
+	if s.FieldIdx < 0 || s.FieldIdx >= len(fields) {
+		return false
+	}
+
+	return fields[s.FieldIdx].Nudge(dir)
+}
+
+func (s *InspectorSystem) Render(cmds *RenderCmds) {
+	s.overlay.Clear()
+
+	if !s.Enabled {
+		return
+	}
+
+	if s.font == nil {
+		s.font = createFontFace(30, 72)
+	}
+
+	lines := []string{"INSPECTOR nothing selected"}
+	if ent := s.SelectedEnt(); ent != nil {
+		trans := ent.GetTransformComponent()
+		x, y, w, h := trans.Postion.X, trans.Postion.Y, trans.Size.X, trans.Size.Y
+		clr := color.RGBA{R: 255, G: 255, A: 255}
+		ebitenutil.DrawLine(s.overlay, x, y, x+w, y, clr)
+		ebitenutil.DrawLine(s.overlay, x+w, y, x+w, y+h, clr)
+		ebitenutil.DrawLine(s.overlay, x+w, y+h, x, y+h, clr)
+		ebitenutil.DrawLine(s.overlay, x, y+h, x, y, clr)
+
+		name := typeName(ent)
+		if name == "" {
+			name = "entity"
+		}
+		lines = []string{fmt.Sprintf("INSPECTOR %s %d", name, s.Selected)}
+
+		fields := s.Fields()
+		start := 0
+		if s.FieldIdx >= inspectorMaxLines/2 {
+			start = s.FieldIdx - inspectorMaxLines/2
+		}
+		for i := start; i < len(fields) && i < start+inspectorMaxLines; i++ {
+			cursor := "  "
+			if i == s.FieldIdx {
+				cursor = "> "
+			}
+			lines = append(lines, cursor+fields[i].Name+" "+fields[i].String())
+		}
+	}
+
+	left := float64(windowWidth - inspectorPanelWidth)
+	ebitenutil.DrawRect(s.overlay, left, 0, inspectorPanelWidth, float64((len(lines)+1)*inspectorLineHeight), color.RGBA{A: 200})
+	for i, line := range lines {
+		text.Draw(s.overlay, line, s.font, int(left)+10, (i+1)*inspectorLineHeight, color.White)
+	}
+
+	*cmds = append(*cmds, &RenderImageCmd{
+		Image:   s.overlay,
+		Options: &ebiten.DrawImageOptions{},
+		Layer:   ImageLayerDebug,
+	})
+}
+
+func (s *InspectorSystem) Add(r Inspectable) {
+	s.ents[r.GetBasicEntity().ID()] = r
+}
+
+func (s *InspectorSystem) Remove(e ecs.BasicEntity) {
+	delete(s.ents, e.ID())
+	if e.ID() == s.Selected {
+		s.Selected = 0
+		s.FieldIdx = 0
+	}
+}
+
+func (s *InspectorSystem) AddByInterface(o ecs.Identifier) {
+	s.Add(o.(Inspectable))
+}
+
+// InspectorField is one value on a component e.g. TransformComponent.Postion.X
+type InspectorField struct {
+	Name  string
+	value reflect.Value
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// inspectorFields finds every component the entity is made of, components
+// are the embedded pointers to types ending in Component
+func inspectorFields(ent interface{}) []InspectorField {
+	v := reflect.ValueOf(ent)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var result []InspectorField
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.Ptr || field.IsNil() || field.Elem().Kind() != reflect.Struct {
+			continue
+		}
+
+		name := field.Elem().Type().Name()
+		if !strings.HasSuffix(name, "Component") {
+			continue
+		}
+
+		result = appendInspectorFields(result, name, field.Elem(), 1)
+	}
+
+	return result
+}
+
+func appendInspectorFields(result []InspectorField, prefix string, v reflect.Value, depth int) []InspectorField {
+	for i := 0; i < v.NumField(); i++ {
+		fieldType := v.Type().Field(i)
+		if fieldType.PkgPath != "" {
+			continue
+		}
+
+		field := v.Field(i)
+		name := prefix + "." + fieldType.Name
+		switch field.Kind() {
+		case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			continue
+		case reflect.Struct:
+			if depth < inspectorMaxDepth {
+				result = appendInspectorFields(result, name, field, depth+1)
+				continue
+			}
+		}
+
+		result = append(result, InspectorField{Name: name, value: field})
+	}
+
+	return result
+}
+
+// Editable is true for numbers and bools
+func (f InspectorField) Editable() bool {
+	if !f.value.CanSet() {
+		return false
+	}
+
+	switch f.value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		return true
+	}
+
+	return false
+}
+
+func (f InspectorField) Nudge(dir int) bool {
+	if !f.Editable() {
+		return false
+	}
+
+	switch f.value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		step := int64(1)
+		if f.value.Type() == durationType {
+			step = int64(inspectorDurationStep)
+		}
+		f.value.SetInt(f.value.Int() + int64(dir)*step)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if dir < 0 && f.value.Uint() == 0 {
+			return false
+		}
+		f.value.SetUint(uint64(int64(f.value.Uint()) + int64(dir)))
+	case reflect.Float32, reflect.Float64:
+		f.value.SetFloat(f.value.Float() + float64(dir)*inspectorFloatStep)
+	case reflect.Bool:
+		f.value.SetBool(!f.value.Bool())
+	}
+
+	return true
+}
+
+func inspectorTags(tags []int) string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = entity.TagName(tag)
+	}
+
+	return "[" + strings.Join(names, " ") + "]"
+}
+
+func (f InspectorField) String() string {
+	v := f.value
+
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return value.String()
+	case components.CollisionEvents:
+		var events []string
+		for _, event := range value {
+			events = append(events, inspectorTags(event.Tags))
+		}
+		return "[" + strings.Join(events, " ") + "]"
+	}
+
+	if strings.HasSuffix(f.Name, ".Tags") && v.Type() == reflect.TypeOf([]int{}) {
+		return inspectorTags(v.Interface().([]int))
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("%.2f", v.Float())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		return fmt.Sprintf("%T", v.Interface())
+	case reflect.Map, reflect.Slice:
+		return fmt.Sprintf("%T len %d", v.Interface(), v.Len())
+	case reflect.Struct:
+		return fmt.Sprintf("%T", v.Interface())
+	}
+
+	return fmt.Sprintf("%v", v.Interface())
+}
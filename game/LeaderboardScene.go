@@ -0,0 +1,111 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+	"github.com/sardap/walk-good-maybe-hd/scores"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+	"golang.org/x/image/font"
+)
+
+// LeaderboardScene shows the high scores until select or pause is pressed
+type LeaderboardScene struct {
+	world     *ecs.World
+	inputEnt  *entity.InputEnt
+	table     *scores.Table
+	message   string
+	titleFont font.Face
+	rowFont   font.Face
+}
+
+func (l *LeaderboardScene) Start(game *Game) {
+	l.world = &ecs.World{}
+
+	var inputable *Inputable
+	AddSystemInterface(l.world, CreateInputSystem(), inputable, nil)
+
+	l.inputEnt = entity.CreateMenuInput()
+	game.applyInputSettings(l.inputEnt.InputComponent)
+	l.world.AddEntity(l.inputEnt)
+
+	l.message = ""
+	table, err := game.Scores().Load()
+	if err != nil {
+		l.message = fmt.Sprintf("UNABLE TO LOAD SCORES %v", err)
+		table = &scores.Table{}
+	}
+	l.table = table
+
+	l.titleFont = createFontFace(120, 72)
+	l.rowFont = createFontFace(50, 72)
+}
+
+func (l *LeaderboardScene) End(*Game) {
+	l.world = nil
+	l.inputEnt = nil
+	l.table = nil
+}
+
+func (l *LeaderboardScene) Transition() Transition {
+	return Transition{
+		Type:     TransitionWipe,
+		Duration: 500 * time.Millisecond,
+	}
+}
+
+func (l *LeaderboardScene) Update(dt time.Duration, game *Game) {
+	updateWorld(l.world, float32(dt)/float32(time.Second))
+
+	if l.inputEnt.InputJustPressed(components.InputKindSelect) || l.inputEnt.InputJustPressed(components.InputKindPause) {
+		defer game.ChangeScene(&TitleScene{})
+	}
+}
+
+// rows are the entries as they're shown best first
+func (l *LeaderboardScene) rows() []string {
+	var result []string
+	for i, entry := range l.table.Entries {
+		result = append(result, fmt.Sprintf(
+			"%2d %s %08d %5dM %s %s",
+			i+1, entry.Name, entry.Score, int(entry.Distance),
+			utility.EncodeSeed(entry.Seed), entry.Date.Format("2006-01-02"),
+		))
+	}
+
+	return result
+}
+
+func (l *LeaderboardScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{B: 255, A: 255})
+
+	drawCentered := func(str string, face font.Face, y int, clr color.Color) {
+		b := text.BoundString(face, str)
+		text.Draw(screen, str, face, windowWidth/2-b.Dx()/2, y, clr)
+	}
+
+	drawCentered("HIGH SCORES", l.titleFont, 250, color.White)
+
+	rows := l.rows()
+	if len(rows) == 0 {
+		drawCentered("NO SCORES YET", l.rowFont, 700, color.White)
+	}
+
+	y := 420
+	for _, row := range rows {
+		text.Draw(screen, row, l.rowFont, 200, y, color.White)
+		y += 90
+	}
+
+	if l.message != "" {
+		drawCentered(l.message, l.rowFont, 1380, color.RGBA{R: 255, A: 255})
+	}
+
+	drawCentered("SELECT TO GO BACK", l.rowFont, 1500, color.White)
+}
@@ -1,195 +1,368 @@
-package game
-
-import (
-	"math/rand"
-	"time"
-
-	"github.com/EngoEngine/ecs"
-	"github.com/SolarLune/resolv"
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/sardap/walk-good-maybe-hd/components"
-	"github.com/sardap/walk-good-maybe-hd/entity"
-	"github.com/sardap/walk-good-maybe-hd/math"
-	"github.com/sardap/walk-good-maybe-hd/utility"
-)
-
-type MainGameScene struct {
-	Rand           *rand.Rand
-	Space          *resolv.Space
-	World          *ecs.World
-	ScrollingSpeed math.Vector2
-	Gravity        float64
-	State          gameState
-	Level          *Level
-	InputEnt       *entity.InputEnt
-	TimeElapsed    time.Duration
-}
-
-func (m *MainGameScene) addSystems(audioCtx *audio.Context) {
-	var animeable *Animeable
-	m.World.AddSystemInterface(CreateAnimeSystem(), animeable, nil)
-
-	var renderable *ImageRenderable
-	m.World.AddSystemInterface(CreateImageRenderSystem(), renderable, nil)
-
-	var tileImageRenderable *TileImageRenderable
-	m.World.AddSystemInterface(CreateTileImageRenderSystem(), tileImageRenderable, nil)
-
-	var textRenderable *TextRenderable
-	m.World.AddSystemInterface(CreateTextRenderSystem(), textRenderable, nil)
-
-	var inputable *Inputable
-	m.World.AddSystemInterface(CreateInputSystem(), inputable, nil)
-
-	m.InputEnt = entity.CreateDebugInput()
-	m.World.AddEntity(m.InputEnt)
-
-	var soundable *Soundable
-	m.World.AddSystemInterface(CreateSoundSystem(audioCtx), soundable, nil)
-
-	var gameRuleable *GameRuleable
-	m.World.AddSystemInterface(CreateGameRuleSystem(m), gameRuleable, nil)
-
-	var velocityable *Velocityable
-	m.World.AddSystemInterface(CreateVelocitySystem(m.Space), velocityable, nil)
-
-	var dumbVelocityable *DumbVelocityable
-	var exVelocityable *ExDumbVelocityable
-	m.World.AddSystemInterface(CreateDumbVelocitySystem(), dumbVelocityable, exVelocityable)
-
-	var resolvable *Resolvable
-	m.World.AddSystemInterface(CreateResolvSystem(m.Space, m.InputEnt), resolvable, nil)
-
-	var playerable *Playerable
-	m.World.AddSystemInterface(CreatePlayerSystem(m), playerable, nil)
-
-	var lifeable *Lifeable
-	m.World.AddSystemInterface(CreateLifeSystem(), lifeable, nil)
-
-	m.World.AddSystemInterface(CreateMainGameUiSystem(), gameRuleable, nil)
-
-	var enemyBiscuitable *EnemyBiscuitable
-	m.World.AddSystemInterface(CreateEnemyBiscuitSystem(m.Space), enemyBiscuitable, nil)
-}
-
-func (m *MainGameScene) addEnts() {
-	m.World.AddEntity(entity.CreateCityMusic())
-
-	cityBackground := entity.CreateCityBackground()
-	cityBackground.ImageComponent.Layer = ImageLayerCityLayer
-	m.World.AddEntity(cityBackground)
-
-	cityBackground = entity.CreateCityBackground()
-	cityBackground.ImageComponent.Layer = ImageLayerCityLayer
-	cityBackground.Postion.X = cityBackground.TransformComponent.Size.X
-	m.World.AddEntity(cityBackground)
-
-	citySky := entity.CreateCitySkyBackground()
-	citySky.Layer = ImageLayerBottom
-	m.World.AddEntity(citySky)
-
-	cityFog := entity.CreateCityFogBackground()
-	cityFog.ImageComponent.Layer = ImageLayercityFogLayer
-	m.World.AddEntity(cityFog)
-
-	cityFog = entity.CreateCityFogBackground()
-	cityFog.ImageComponent.Layer = ImageLayercityFogLayer
-	cityFog.Postion.X = cityFog.TransformComponent.Size.X
-	m.World.AddEntity(cityFog)
-
-	player := entity.CreatePlayer()
-	player.TileImageComponent.Layer = ImageLayerObjects
-	player.MaxHp = 300
-	player.HP = player.MaxHp
-	player.JumpPower = startingPlayerJumpPower
-	player.AirHorzSpeedModifier = startingPlayerAirHorzMod
-	m.World.AddEntity(player)
-
-	leftKillBox := entity.CreateKillBox()
-	leftKillBox.Postion.X = -500 - leftKillBox.Size.X
-	m.World.AddEntity(leftKillBox)
-
-	rightKillBox := entity.CreateKillBox()
-	rightKillBox.Postion.X = windowWidth + 1000 + rightKillBox.Size.X
-	m.World.AddEntity(rightKillBox)
-
-	bottomKillBox := entity.CreateKillBox()
-	bottomKillBox.Size.X = windowWidth
-	bottomKillBox.Postion.Y = windowHeight + 100
-	m.World.AddEntity(bottomKillBox)
-}
-
-func (m *MainGameScene) Start(game *Game) {
-	m.World = &ecs.World{}
-	m.Space = resolv.NewSpace()
-	m.ScrollingSpeed = math.Vector2{}
-	m.Gravity = startingGravity
-	m.Rand = rand.New(rand.NewSource(time.Now().Unix()))
-	m.State = gameStateStarting
-	m.Level = &Level{
-		Width:  windowWidth,
-		Height: windowHeight,
-	}
-
-	m.addSystems(game.audioCtx)
-	m.addEnts()
-}
-
-func (m *MainGameScene) End(*Game) {
-	for _, system := range m.World.Systems() {
-		if soundSystem, ok := system.(*SoundSystem); ok {
-			for _, ent := range soundSystem.ents {
-				soundSystem.Remove(*ent.GetBasicEntity())
-			}
-		}
-	}
-
-	m.World = nil
-	m.Space = nil
-	m.ScrollingSpeed = math.Vector2{}
-	m.Rand = nil
-	m.Gravity = 0
-	m.State = gameStateStarting
-	m.TimeElapsed = 0
-	m.Level = nil
-	m.InputEnt = nil
-}
-
-func (m *MainGameScene) Update(dt time.Duration, _ *Game) {
-	if m.InputEnt.InputPressed(components.InputKindFastGameSpeed) {
-		dt *= 20
-	}
-
-	m.World.Update(float32(dt) / float32(time.Second))
-	m.TimeElapsed += dt
-}
-
-func (m *MainGameScene) Draw(screen *ebiten.Image) {
-	queue := RenderCmds{}
-	for _, system := range m.World.Systems() {
-		if render, ok := system.(RenderingSystem); ok {
-			render.Render(&queue)
-		}
-	}
-
-	queue.Sort()
-	for _, item := range queue {
-		item.Draw(screen)
-	}
-}
-
-func (m *MainGameScene) GenerateCityBuildings() {
-	x := m.Level.StartX
-	for x < m.Level.Width {
-		ent := ecs.NewBasic()
-		levelBlock := createRandomLevelBlock(m.Rand, ent)
-		trans := levelBlock.GetTransformComponent()
-		levelBlock.GetTransformComponent().Postion.Y = m.Level.Height - trans.Size.Y
-		levelBlock.GetTransformComponent().Postion.X = x
-		x += levelBlock.Size.X + float64(utility.RandRange(m.Rand, minSpaceBetweenBuildings, minSpaceBetweenBuildings+20*scaleMultiplier))
-		m.World.AddEntity(levelBlock)
-		populateLevelBlock(m.Rand, m.World, levelBlock)
-	}
-	m.Level.StartX = x
-}
+package game
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/SolarLune/resolv"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+	"github.com/sardap/walk-good-maybe-hd/math"
+	"github.com/sardap/walk-good-maybe-hd/replay"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+)
+
+type MainGameScene struct {
+	// Zero picks a random seed
+	Seed int64
+	// Set to record the player's input each tick
+	Recording *replay.Replay
+	// Set to save the recording there when the scene ends, one is made if
+	// Recording isn't set
+	RecordPath string
+	// Set to play back a recording instead of reading the player's input
+	Replay         *replay.Replay
+	replayPlayer   *replay.Player
+	Player         *entity.Player
+	Rand           *rand.Rand
+	Space          *resolv.Space
+	World          *ecs.World
+	Events         *EventBus
+	ScrollingSpeed math.Vector2
+	Gravity        float64
+	State          gameState
+	Level          *Level
+	InputEnt       *entity.InputEnt
+	TimeElapsed    time.Duration
+	Stats          RunStats
+	// dyingTime is how long since the player died
+	dyingTime time.Duration
+}
+
+const (
+	// deathFreeze is how long everything stops when the player dies
+	deathFreeze = 600 * time.Millisecond
+	// deathFall is how long the player falls off the screen before game over
+	deathFall = 1500 * time.Millisecond
+	// deathHop is roughly how high the player hops before falling
+	deathHop = 300
+)
+
+func (m *MainGameScene) addSystems(game *Game) {
+	// Has to go first so the other systems can find the bus
+	AddSystem(m.World, CreateEventSystem(m.Events))
+	m.watchPlayer()
+
+	// Systems which don't say otherwise update in the order they're added
+
+	var inputable *Inputable
+	AddSystemInterface(m.World, CreateInputSystem(), inputable, nil)
+
+	m.InputEnt = entity.CreateDebugInput()
+	game.applyInputSettings(m.InputEnt.InputComponent)
+	m.World.AddEntity(m.InputEnt)
+
+	var timerable *Timerable
+	var tweenable *Tweenable
+	AddSystemInterface(m.World, CreateTimerSystem(), []interface{}{timerable, tweenable}, nil)
+
+	var enemyBiscuitable *EnemyBiscuitable
+	AddSystemInterface(m.World, CreateEnemyBiscuitSystem(m.Space), enemyBiscuitable, nil)
+
+	var playerable *Playerable
+	AddSystemInterface(m.World, CreatePlayerSystem(m), playerable, nil)
+
+	var gameRuleable *GameRuleable
+	AddSystemInterface(m.World, CreateGameRuleSystem(m), gameRuleable, nil)
+
+	AddSystem(m.World, CreateScoreSystem(m))
+
+	var dumbVelocityable *DumbVelocityable
+	var exVelocityable *ExDumbVelocityable
+	AddSystemInterface(m.World, CreateDumbVelocitySystem(), dumbVelocityable, exVelocityable)
+
+	var velocityable *Velocityable
+	AddSystemInterface(m.World, CreateVelocitySystem(m.Space), velocityable, nil)
+
+	var resolvable *Resolvable
+	AddSystemInterface(m.World, CreateResolvSystem(m.Space, m.InputEnt), resolvable, nil)
+
+	var animeable *Animeable
+	AddSystemInterface(m.World, CreateAnimeSystem(), animeable, nil)
+
+	var lifeable *Lifeable
+	AddSystemInterface(m.World, CreateLifeSystem(), lifeable, nil)
+
+	AddSystem(m.World, CreateEffectSystem(m))
+
+	var soundable *Soundable
+	soundSystem := CreateSoundSystem(game.audioCtx)
+	soundSystem.SetVolume(game.Volume())
+	AddSystemInterface(m.World, soundSystem, soundable, nil)
+
+	var inspectable *Inspectable
+	AddSystemInterface(m.World, CreateInspectorSystem(m.InputEnt), inspectable, nil)
+
+	var imageReloadable *ImageReloadable
+	var tileImageReloadable *TileImageReloadable
+	AddSystemInterface(m.World, CreateAssetReloadSystem(), []interface{}{imageReloadable, tileImageReloadable}, nil)
+
+	AddSystemInterface(m.World, CreateMainGameUiSystem(m), gameRuleable, nil)
+
+	var textRenderable *TextRenderable
+	AddSystemInterface(m.World, CreateTextRenderSystem(), textRenderable, nil)
+
+	var tileImageRenderable *TileImageRenderable
+	AddSystemInterface(m.World, CreateTileImageRenderSystem(), tileImageRenderable, nil)
+
+	var renderable *ImageRenderable
+	AddSystemInterface(m.World, CreateImageRenderSystem(), renderable, nil)
+}
+
+// watchPlayer starts dying when the player does
+func (m *MainGameScene) watchPlayer() {
+	m.Events.Subscribe(EventKindEntityDied, func(e Event) {
+		if e.(EntityDied).Ent == m.Player {
+			m.startDying()
+		}
+	})
+}
+
+func (m *MainGameScene) startDying() {
+	m.State = gameStateDying
+	m.ScrollingSpeed = math.Vector2{}
+	m.dyingTime = 0
+}
+
+func (m *MainGameScene) addEnts(game *Game) {
+	m.World.AddEntity(entity.CreateCityMusic())
+
+	cityBackground := entity.CreateCityBackground()
+	cityBackground.ImageComponent.Layer = ImageLayerCityLayer
+	m.World.AddEntity(cityBackground)
+
+	cityBackground = entity.CreateCityBackground()
+	cityBackground.ImageComponent.Layer = ImageLayerCityLayer
+	cityBackground.Postion.X = cityBackground.TransformComponent.Size.X
+	m.World.AddEntity(cityBackground)
+
+	citySky := entity.CreateCitySkyBackground()
+	citySky.Layer = ImageLayerBottom
+	m.World.AddEntity(citySky)
+
+	cityFog := entity.CreateCityFogBackground()
+	cityFog.ImageComponent.Layer = ImageLayercityFogLayer
+	m.World.AddEntity(cityFog)
+
+	cityFog = entity.CreateCityFogBackground()
+	cityFog.ImageComponent.Layer = ImageLayercityFogLayer
+	cityFog.Postion.X = cityFog.TransformComponent.Size.X
+	m.World.AddEntity(cityFog)
+
+	player := entity.CreatePlayer()
+	player.TileImageComponent.Layer = ImageLayerObjects
+	player.MaxHp = 300
+	player.HP = player.MaxHp
+	player.JumpPower = startingPlayerJumpPower
+	player.AirHorzSpeedModifier = startingPlayerAirHorzMod
+	player.InputMode = game.Settings().InputMode
+	game.applyInputSettings(player.InputComponent)
+	if m.replayPlayer != nil {
+		player.InputMode = components.InputModeKeyboard
+		player.Keyboard = m.replayPlayer.KeyboardInputType()
+		player.Gamepad = m.replayPlayer.GamepadInputType()
+	}
+	m.Player = player
+	m.World.AddEntity(player)
+
+	leftKillBox := entity.CreateKillBox()
+	leftKillBox.Postion.X = -500 - leftKillBox.Size.X
+	m.World.AddEntity(leftKillBox)
+
+	rightKillBox := entity.CreateKillBox()
+	rightKillBox.Postion.X = windowWidth + 1000 + rightKillBox.Size.X
+	m.World.AddEntity(rightKillBox)
+
+	bottomKillBox := entity.CreateKillBox()
+	bottomKillBox.Size.X = windowWidth
+	bottomKillBox.Postion.Y = windowHeight + 100
+	m.World.AddEntity(bottomKillBox)
+}
+
+func (m *MainGameScene) Start(game *Game) {
+	m.World = &ecs.World{}
+	m.Events = CreateEventBus()
+	m.Space = resolv.NewSpace()
+	m.ScrollingSpeed = math.Vector2{}
+	m.Gravity = startingGravity
+	if m.Replay != nil {
+		m.Seed = m.Replay.Seed
+		m.replayPlayer = replay.CreatePlayer(m.Replay)
+	}
+	if m.Seed == 0 {
+		m.Seed = utility.RandomSeed(rand.New(rand.NewSource(time.Now().UnixNano())))
+	}
+	if m.RecordPath != "" && m.Recording == nil {
+		m.Recording = &replay.Replay{}
+	}
+	if m.Recording != nil {
+		m.Recording.Seed = m.Seed
+		m.Recording.TickRate = game.TickRate()
+		m.Recording.Frames = nil
+	}
+	m.Rand = rand.New(rand.NewSource(m.Seed))
+	m.State = gameStateStarting
+	m.Stats = RunStats{Seed: m.Seed}
+	m.dyingTime = 0
+	m.Level = &Level{
+		Width:  windowWidth,
+		Height: windowHeight,
+	}
+
+	m.addSystems(game)
+	m.addEnts(game)
+}
+
+func (m *MainGameScene) Transition() Transition {
+	return Transition{
+		Type:     TransitionIris,
+		Duration: time.Second,
+	}
+}
+
+func (m *MainGameScene) End(*Game) {
+	if m.RecordPath != "" && m.Recording != nil {
+		if err := replay.SaveFile(m.RecordPath, m.Recording); err != nil {
+			log.Printf("unable to save recording: %v", err)
+		}
+	}
+
+	for _, system := range m.World.Systems() {
+		if soundSystem, ok := system.(*SoundSystem); ok {
+			for _, ent := range soundSystem.ents {
+				soundSystem.Remove(*ent.GetBasicEntity())
+			}
+		}
+	}
+	endSystems(m.World)
+
+	m.World = nil
+	m.Events = nil
+	m.Space = nil
+	m.ScrollingSpeed = math.Vector2{}
+	m.Rand = nil
+	m.Gravity = 0
+	m.State = gameStateStarting
+	m.TimeElapsed = 0
+	m.dyingTime = 0
+	m.Level = nil
+	m.InputEnt = nil
+	m.Player = nil
+	m.replayPlayer = nil
+}
+
+func (m *MainGameScene) pausePressed() bool {
+	// Replays can't pause the game
+	if m.replayPlayer != nil {
+		return false
+	}
+
+	return m.InputEnt.InputJustPressed(components.InputKindPause) ||
+		(m.Player != nil && m.Player.InputJustPressed(components.InputKindPause))
+}
+
+// fastGameSpeed comes from the replay when there is one since it changes how
+// far every tick goes
+func (m *MainGameScene) fastGameSpeed() bool {
+	if m.replayPlayer != nil {
+		return m.replayPlayer.Frame().FastGameSpeed
+	}
+
+	return m.InputEnt.InputPressed(components.InputKindFastGameSpeed)
+}
+
+func (m *MainGameScene) Update(dt time.Duration, game *Game) {
+	fast := m.fastGameSpeed()
+	if fast {
+		dt *= 20
+	}
+
+	if m.State == gameStateDying {
+		m.updateDying(dt, game)
+		return
+	}
+
+	updateWorld(m.World, float32(dt)/float32(time.Second))
+	m.TimeElapsed += dt
+	m.Stats.Time = m.TimeElapsed
+
+	if m.Recording != nil && m.Player != nil {
+		m.Recording.Record(m.Player.MovementComponent, fast)
+	}
+
+	if m.replayPlayer != nil {
+		m.replayPlayer.Advance()
+	}
+
+	if m.pausePressed() {
+		game.PushScene(&PauseScene{Target: m})
+	}
+}
+
+// updateDying holds everything still for a moment then lets the player fall
+// off the screen before showing how the run went
+func (m *MainGameScene) updateDying(dt time.Duration, game *Game) {
+	m.dyingTime += dt
+	if m.dyingTime <= deathFreeze {
+		return
+	}
+
+	updateWorld(m.World, float32(dt)/float32(time.Second))
+
+	if m.dyingTime >= deathFreeze+deathFall {
+		game.ChangeScene(&GameOverScene{Stats: m.Stats})
+	}
+}
+
+func (m *MainGameScene) Covered(*Game) {
+	for _, system := range m.World.Systems() {
+		if soundSystem, ok := system.(*SoundSystem); ok {
+			soundSystem.PauseAll()
+		}
+	}
+}
+
+func (m *MainGameScene) Uncovered(*Game) {
+	for _, system := range m.World.Systems() {
+		if soundSystem, ok := system.(*SoundSystem); ok {
+			soundSystem.ResumeAll()
+		}
+	}
+}
+
+func (m *MainGameScene) Draw(screen *ebiten.Image) {
+	for _, item := range renderWorld(m.World) {
+		item.Draw(screen)
+	}
+}
+
+func (m *MainGameScene) GenerateCityBuildings() {
+	x := m.Level.StartX
+	for x < m.Level.Width {
+		ent := ecs.NewBasic()
+		levelBlock := createRandomLevelBlock(m.Rand, ent)
+		trans := levelBlock.GetTransformComponent()
+		levelBlock.GetTransformComponent().Postion.Y = m.Level.Height - trans.Size.Y
+		levelBlock.GetTransformComponent().Postion.X = x
+		x += levelBlock.Size.X + float64(utility.RandRange(m.Rand, minSpaceBetweenBuildings, minSpaceBetweenBuildings+20*scaleMultiplier))
+		m.World.AddEntity(levelBlock)
+		for _, ledge := range createLedges(m.Rand, levelBlock) {
+			m.World.AddEntity(ledge)
+		}
+		populateLevelBlock(m.Rand, m.World, levelBlock)
+	}
+	m.Level.StartX = x
+}
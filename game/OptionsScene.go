@@ -0,0 +1,352 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+	"github.com/sardap/walk-good-maybe-hd/settings"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+	"golang.org/x/image/font"
+)
+
+type optionsRowType int
+
+const (
+	optionsRowVolume optionsRowType = iota
+	optionsRowInputMode
+	optionsRowFullscreen
+	optionsRowWindowScale
+	optionsRowKey
+	optionsRowButton
+	optionsRowSave
+	optionsRowReset
+	optionsRowBack
+)
+
+type optionsRow struct {
+	rowType optionsRowType
+	// kind is what gets rebound for key and button rows
+	kind components.InputKind
+}
+
+const (
+	optionsVisibleRows = 14
+	volumeStep         = 0.1
+)
+
+var (
+	rebindableKeys = []components.InputKind{
+		components.InputKindMoveUp, components.InputKindMoveDown,
+		components.InputKindMoveLeft, components.InputKindMoveRight,
+		components.InputKindJump, components.InputKindShoot,
+		components.InputKindSelect, components.InputKindPause,
+		components.InputKindKaraokeA, components.InputKindKaraokeB,
+		components.InputKindKaraokeX, components.InputKindKaraokeY,
+	}
+	rebindableButtons = []components.InputKind{
+		components.InputKindJump, components.InputKindShoot,
+		components.InputKindSelect, components.InputKindPause,
+		components.InputKindKaraokeA, components.InputKindKaraokeB,
+		components.InputKindKaraokeX, components.InputKindKaraokeY,
+	}
+)
+
+// OptionsScene edits a copy of the settings which only replaces the real
+// ones once saved
+type OptionsScene struct {
+	Settings    *settings.Settings
+	rows        []optionsRow
+	selectedIdx int
+	// waiting is true while the selected row is waiting for a key or button
+	waiting bool
+	// Whatever was held when waiting started is ignored until it's let go
+	// otherwise select would bind itself
+	heldKeys    map[ebiten.Key]bool
+	heldButtons map[ebiten.GamepadButton]bool
+	message     string
+	world       *ecs.World
+	inputEnt    *entity.InputEnt
+	font        font.Face
+}
+
+func (o *OptionsScene) Start(game *Game) {
+	o.Settings = game.Settings().Copy()
+	o.selectedIdx = 0
+	o.waiting = false
+	o.message = ""
+
+	o.rows = []optionsRow{
+		{rowType: optionsRowVolume},
+		{rowType: optionsRowInputMode},
+		{rowType: optionsRowFullscreen},
+		{rowType: optionsRowWindowScale},
+	}
+	for _, kind := range rebindableKeys {
+		o.rows = append(o.rows, optionsRow{rowType: optionsRowKey, kind: kind})
+	}
+	for _, kind := range rebindableButtons {
+		o.rows = append(o.rows, optionsRow{rowType: optionsRowButton, kind: kind})
+	}
+	o.rows = append(o.rows,
+		optionsRow{rowType: optionsRowSave},
+		optionsRow{rowType: optionsRowReset},
+		optionsRow{rowType: optionsRowBack},
+	)
+
+	o.world = &ecs.World{}
+
+	var inputable *Inputable
+	AddSystemInterface(o.world, CreateInputSystem(), inputable, nil)
+
+	o.inputEnt = entity.CreateMenuInput()
+	game.applyInputSettings(o.inputEnt.InputComponent)
+	o.world.AddEntity(o.inputEnt)
+
+	o.font = createFontFace(60, 72)
+}
+
+func (o *OptionsScene) End(*Game) {
+	o.world = nil
+	o.inputEnt = nil
+}
+
+func (o *OptionsScene) Transition() Transition {
+	return Transition{
+		Type:     TransitionWipe,
+		Duration: 500 * time.Millisecond,
+	}
+}
+
+// TakesTextInput stops R from leaving while a key is being bound
+func (o *OptionsScene) TakesTextInput() bool {
+	return o.waiting
+}
+
+func inputKindLabel(kind components.InputKind) string {
+	return strings.ToUpper(strings.ReplaceAll(kind.String(), "_", " "))
+}
+
+func (o *OptionsScene) rowText(row optionsRow) string {
+	s := o.Settings
+
+	switch row.rowType {
+	case optionsRowVolume:
+		return fmt.Sprintf("VOLUME %d%%", int(s.Volume*100+0.5))
+	case optionsRowInputMode:
+		return fmt.Sprintf("INPUT %s", s.InputMode)
+	case optionsRowFullscreen:
+		if s.Fullscreen {
+			return "FULLSCREEN ON"
+		}
+		return "FULLSCREEN OFF"
+	case optionsRowWindowScale:
+		if s.WindowScale == 0 {
+			return "WINDOW SCALE DEFAULT"
+		}
+		return fmt.Sprintf("WINDOW SCALE %dX", s.WindowScale)
+	case optionsRowKey:
+		key, ok := s.Keyboard[row.kind]
+		value := "NONE"
+		if ok {
+			value = key.String()
+		}
+		return fmt.Sprintf("KEY %s %s", inputKindLabel(row.kind), value)
+	case optionsRowButton:
+		btn, ok := s.Gamepad[row.kind]
+		value := "NONE"
+		if ok {
+			value = fmt.Sprintf("%d", btn)
+		}
+		return fmt.Sprintf("PAD %s %s", inputKindLabel(row.kind), value)
+	case optionsRowSave:
+		return "SAVE"
+	case optionsRowReset:
+		return "RESET TO DEFAULTS"
+	case optionsRowBack:
+		return "BACK"
+	}
+
+	panic("Unknown options row")
+}
+
+// adjust changes the selected value left or right
+func (o *OptionsScene) adjust(dir int) {
+	s := o.Settings
+
+	switch o.rows[o.selectedIdx].rowType {
+	case optionsRowVolume:
+		s.Volume = utility.ClampFloat64(s.Volume+float64(dir)*volumeStep, 0, 1)
+	case optionsRowInputMode:
+		if s.InputMode == components.InputModeKeyboard {
+			s.InputMode = components.InputModeGamepad
+		} else {
+			s.InputMode = components.InputModeKeyboard
+		}
+	case optionsRowFullscreen:
+		s.Fullscreen = !s.Fullscreen
+	case optionsRowWindowScale:
+		s.WindowScale = utility.WrapInt(s.WindowScale+dir, 0, settings.MaxScale+1)
+	}
+}
+
+func (o *OptionsScene) activate(game *Game) {
+	switch o.rows[o.selectedIdx].rowType {
+	case optionsRowKey, optionsRowButton:
+		o.waiting = true
+		o.message = ""
+
+		o.heldKeys = make(map[ebiten.Key]bool)
+		for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+			if ebiten.IsKeyPressed(key) {
+				o.heldKeys[key] = true
+			}
+		}
+
+		o.heldButtons = make(map[ebiten.GamepadButton]bool)
+		for _, id := range ebiten.GamepadIDs() {
+			for btn := ebiten.GamepadButton(0); btn <= ebiten.GamepadButtonMax; btn++ {
+				if ebiten.IsGamepadButtonPressed(id, btn) {
+					o.heldButtons[btn] = true
+				}
+			}
+		}
+	case optionsRowSave:
+		if err := game.SaveSettings(o.Settings.Copy()); err != nil {
+			o.message = fmt.Sprintf("UNABLE TO SAVE %v", err)
+		} else {
+			o.message = "SAVED"
+		}
+	case optionsRowReset:
+		o.Settings = settings.Default()
+		o.message = ""
+	case optionsRowBack:
+		game.ChangeScene(&TitleScene{})
+	default:
+		o.adjust(1)
+	}
+}
+
+// bindKey sets the selected row to key, escape backs out without changing it
+func (o *OptionsScene) bindKey(key ebiten.Key) {
+	row := o.rows[o.selectedIdx]
+	if row.rowType == optionsRowKey && key != ebiten.KeyEscape {
+		o.Settings.Keyboard[row.kind] = key
+	}
+	o.waiting = false
+}
+
+func (o *OptionsScene) bindButton(btn ebiten.GamepadButton) {
+	row := o.rows[o.selectedIdx]
+	if row.rowType == optionsRowButton {
+		o.Settings.Gamepad[row.kind] = btn
+	}
+	o.waiting = false
+}
+
+func (o *OptionsScene) updateWaiting() {
+	for key := range o.heldKeys {
+		if !ebiten.IsKeyPressed(key) {
+			delete(o.heldKeys, key)
+		}
+	}
+
+	ids := ebiten.GamepadIDs()
+	for btn := range o.heldButtons {
+		held := false
+		for _, id := range ids {
+			held = held || ebiten.IsGamepadButtonPressed(id, btn)
+		}
+		if !held {
+			delete(o.heldButtons, btn)
+		}
+	}
+
+	switch o.rows[o.selectedIdx].rowType {
+	case optionsRowKey:
+		for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+			if !o.heldKeys[key] && inpututil.IsKeyJustPressed(key) {
+				o.bindKey(key)
+				return
+			}
+		}
+
+	case optionsRowButton:
+		if !o.heldKeys[ebiten.KeyEscape] && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			o.waiting = false
+			return
+		}
+
+		for _, id := range ids {
+			for btn := ebiten.GamepadButton(0); btn <= ebiten.GamepadButtonMax; btn++ {
+				if !o.heldButtons[btn] && inpututil.IsGamepadButtonJustPressed(id, btn) {
+					o.bindButton(btn)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (o *OptionsScene) Update(dt time.Duration, game *Game) {
+	updateWorld(o.world, float32(dt)/float32(time.Second))
+
+	if o.waiting {
+		o.updateWaiting()
+		return
+	}
+
+	if o.inputEnt.InputJustPressed(components.InputKindSelect) {
+		defer o.activate(game)
+		return
+	}
+
+	if o.inputEnt.InputJustPressed(components.InputKindMoveUp) {
+		o.selectedIdx = utility.WrapInt(o.selectedIdx-1, 0, len(o.rows))
+	}
+
+	if o.inputEnt.InputJustPressed(components.InputKindMoveDown) {
+		o.selectedIdx = utility.WrapInt(o.selectedIdx+1, 0, len(o.rows))
+	}
+
+	if o.inputEnt.InputJustPressed(components.InputKindMoveLeft) {
+		o.adjust(-1)
+	}
+
+	if o.inputEnt.InputJustPressed(components.InputKindMoveRight) {
+		o.adjust(1)
+	}
+}
+
+func (o *OptionsScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{B: 255, A: 255})
+
+	text.Draw(screen, "OPTIONS", o.font, 100, 120, color.White)
+
+	first := utility.ClampInt(o.selectedIdx-optionsVisibleRows/2, 0, len(o.rows)-optionsVisibleRows)
+	y := 250
+	for i := first; i < len(o.rows) && i < first+optionsVisibleRows; i++ {
+		str := o.rowText(o.rows[i])
+		clr := color.Color(color.White)
+		if i == o.selectedIdx {
+			clr = color.RGBA{R: 255, A: 255}
+			if o.waiting {
+				str += " PRESS NEW"
+			}
+		}
+
+		text.Draw(screen, str, o.font, 150, y, clr)
+		y += 85
+	}
+
+	if o.message != "" {
+		text.Draw(screen, o.message, o.font, 100, windowHeight-50, color.White)
+	}
+}
@@ -0,0 +1,138 @@
+package game
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+	"golang.org/x/image/font"
+)
+
+type pauseOption int
+
+const (
+	pauseOptionResume pauseOption = iota
+	pauseOptionRestart
+	pauseOptionQuit
+	pauseOptionLength
+)
+
+func (p pauseOption) String() string {
+	switch p {
+	case pauseOptionResume:
+		return "RESUME"
+	case pauseOptionRestart:
+		return "RESTART"
+	case pauseOptionQuit:
+		return "QUIT TO TITLE"
+	}
+
+	panic("Unknown pause option")
+}
+
+// PauseScene is pushed over the main game which keeps drawing underneath
+type PauseScene struct {
+	Target      *MainGameScene
+	world       *ecs.World
+	inputEnt    *entity.InputEnt
+	selectedIdx pauseOption
+	titleFont   font.Face
+	optionFont  font.Face
+	shade       *ebiten.Image
+}
+
+func (p *PauseScene) Start(game *Game) {
+	p.world = &ecs.World{}
+
+	var inputable *Inputable
+	AddSystemInterface(p.world, CreateInputSystem(), inputable, nil)
+
+	p.inputEnt = entity.CreateMenuInput()
+	game.applyInputSettings(p.inputEnt.InputComponent)
+	p.world.AddEntity(p.inputEnt)
+
+	p.selectedIdx = pauseOptionResume
+	p.titleFont = createFontFace(120, 72)
+	p.optionFont = createFontFace(80, 72)
+
+	p.shade = ebiten.NewImage(1, 1)
+	p.shade.Fill(color.RGBA{A: 160})
+}
+
+func (p *PauseScene) End(*Game) {
+	p.world = nil
+	p.inputEnt = nil
+	if p.shade != nil {
+		p.shade.Dispose()
+		p.shade = nil
+	}
+}
+
+func (p *PauseScene) Transparent() bool {
+	return true
+}
+
+func (p *PauseScene) selected(game *Game, option pauseOption) {
+	switch option {
+	case pauseOptionResume:
+		game.PopScene()
+	case pauseOptionRestart:
+		// Still recording so a bug found after restarting gets saved too
+		game.ChangeScene(&MainGameScene{
+			Seed:       p.Target.Seed,
+			Recording:  p.Target.Recording,
+			RecordPath: p.Target.RecordPath,
+		})
+	case pauseOptionQuit:
+		game.ChangeScene(&TitleScene{})
+	}
+}
+
+func (p *PauseScene) Update(dt time.Duration, game *Game) {
+	updateWorld(p.world, float32(dt)/float32(time.Second))
+
+	if p.inputEnt.InputJustPressed(components.InputKindPause) {
+		defer p.selected(game, pauseOptionResume)
+		return
+	}
+
+	if p.inputEnt.InputJustPressed(components.InputKindSelect) {
+		defer p.selected(game, p.selectedIdx)
+		return
+	}
+
+	if p.inputEnt.InputJustPressed(components.InputKindMoveUp) {
+		p.selectedIdx = pauseOption(utility.WrapInt(int(p.selectedIdx)-1, 0, int(pauseOptionLength)))
+	}
+
+	if p.inputEnt.InputJustPressed(components.InputKindMoveDown) {
+		p.selectedIdx = pauseOption(utility.WrapInt(int(p.selectedIdx)+1, 0, int(pauseOptionLength)))
+	}
+}
+
+func (p *PauseScene) Draw(screen *ebiten.Image) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(windowWidth, windowHeight)
+	screen.DrawImage(p.shade, op)
+
+	const title = "PAUSED"
+	b := text.BoundString(p.titleFont, title)
+	text.Draw(screen, title, p.titleFont, windowWidth/2-b.Dx()/2, 500, color.White)
+
+	y := 800
+	for option := pauseOption(0); option < pauseOptionLength; option++ {
+		clr := color.Color(color.White)
+		if option == p.selectedIdx {
+			clr = color.RGBA{R: 255, A: 255}
+		}
+
+		b := text.BoundString(p.optionFont, option.String())
+		text.Draw(screen, option.String(), p.optionFont, windowWidth/2-b.Dx()/2, y, clr)
+		y += 150
+	}
+}
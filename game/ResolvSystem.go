@@ -1,174 +1,432 @@
-package game
-
-import (
-	"image/color"
-
-	"github.com/EngoEngine/ecs"
-	"github.com/SolarLune/resolv"
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/sardap/walk-good-maybe-hd/components"
-	"github.com/sardap/walk-good-maybe-hd/entity"
-)
-
-type Resolvable interface {
-	ecs.BasicFace
-	components.TransformFace
-	components.IdentityFace
-	components.CollisionFace
-}
-
-type ResolvSystem struct {
-	ents           map[uint64]Resolvable
-	space          *resolv.Space
-	overlay        *ebiten.Image
-	OverlayEnabled bool
-	debugInput     *entity.InputEnt
-}
-
-func CreateResolvSystem(space *resolv.Space, debugInput *entity.InputEnt) *ResolvSystem {
-	return &ResolvSystem{
-		space:      space,
-		debugInput: debugInput,
-	}
-}
-
-func (s *ResolvSystem) Priority() int {
-	return int(systemPriorityResolvSystem)
-}
-
-func (s *ResolvSystem) New(world *ecs.World) {
-	s.ents = make(map[uint64]Resolvable)
-	s.overlay = ebiten.NewImage(windowWidth, windowHeight)
-}
-
-func (s *ResolvSystem) Update(dt float32) {
-	const buffer = 1
-
-	if s.debugInput != nil && s.debugInput.MovementComponent.InputJustReleased(components.InputKindToggleCollsionOverlay) {
-		s.OverlayEnabled = !s.OverlayEnabled
-	}
-
-	for _, ent := range s.ents {
-		colCom := ent.GetCollisionComponent()
-		trans := ent.GetTransformComponent()
-		colShape := colCom.CollisionShape
-
-		colShape.X = trans.Postion.X
-		colShape.Y = trans.Postion.Y
-		colShape.W = trans.Size.X
-		colShape.H = trans.Size.Y
-	}
-
-	for _, ent := range s.ents {
-		colCom := ent.GetCollisionComponent()
-
-		colShape := colCom.CollisionShape
-
-		colCom.Collisions = nil
-
-		colShape.X -= buffer
-		colShape.Y -= buffer
-		colShape.W += buffer * 2
-		colShape.H += buffer * 2
-
-		for _, collidingShape := range s.space.Collisions(colShape) {
-			colCom.Collisions = append(colCom.Collisions, &components.CollisionEvent{
-				Tags: collidingShape.GetTags(),
-			})
-
-			// apply damage
-			if damage, ok := ent.(components.DamageFace); ok {
-				damageCom := damage.GetDamageComponent()
-				// That's right I broke the rules
-				if other, ok := collidingShape.GetData().(components.LifeFace); ok {
-					otherLifeCom := other.GetLifeComponent()
-					otherLifeCom.DamageEvents = append(otherLifeCom.DamageEvents, &components.DamageEvent{
-						Damage: damageCom.BaseDamage,
-					})
-				}
-			}
-		}
-
-		colShape.X += buffer
-		colShape.Y += buffer
-		colShape.W -= buffer * 2
-		colShape.H -= buffer * 2
-	}
-}
-
-func (s *ResolvSystem) Render(cmds *RenderCmds) {
-	s.overlay.Fill(color.RGBA{0, 0, 0, 0})
-
-	if !s.OverlayEnabled {
-		return
-	}
-
-	for _, ent := range s.ents {
-		if !ent.GetCollisionComponent().Active {
-			continue
-		}
-
-		x1 := ent.GetTransformComponent().Postion.X
-		y1 := ent.GetTransformComponent().Postion.Y
-		w := ent.GetTransformComponent().Size.X
-		h := ent.GetTransformComponent().Size.Y
-
-		clr := color.RGBA{255, 0, 0, 255}
-		// Left Top to Right Top
-		ebitenutil.DrawLine(s.overlay, x1, y1, x1+w, y1, clr)
-		// Right Top to Right Bottom
-		ebitenutil.DrawLine(s.overlay, x1+w, y1, x1+w, y1+h, clr)
-		// Right Bottom to Left Bottom
-		ebitenutil.DrawLine(s.overlay, x1+w, y1+h, x1, y1+h, clr)
-		// Left Bottom to Left top
-		ebitenutil.DrawLine(s.overlay, x1, y1+h, x1, y1, clr)
-	}
-
-	op := &ebiten.DrawImageOptions{}
-	*cmds = append(*cmds, &RenderImageCmd{
-		Image:   s.overlay,
-		Options: op,
-		Layer:   ImageLayerDebug,
-	})
-}
-
-func (s *ResolvSystem) Add(r Resolvable) {
-	s.ents[r.GetBasicEntity().ID()] = r
-
-	if r.GetCollisionComponent().CollisionShape != nil {
-		if !s.space.Contains(r.GetCollisionComponent().CollisionShape) {
-			s.space.Add(r.GetCollisionComponent().CollisionShape)
-		}
-		return
-	}
-
-	trans := r.GetTransformComponent()
-	ident := r.GetIdentityComponent()
-
-	rectangle := resolv.NewRectangle(
-		trans.Postion.X, trans.Postion.Y,
-		trans.Size.X, trans.Size.Y,
-	)
-
-	rectangle.AddTags(ident.Tags...)
-
-	// THIS DATA SHOULD ONLY BE USED FOR TESTING
-	rectangle.Data = r
-
-	s.space.Add(rectangle)
-
-	r.GetCollisionComponent().CollisionShape = rectangle
-}
-
-func (s *ResolvSystem) Remove(e ecs.BasicEntity) {
-	if ent, ok := s.ents[e.ID()]; ok {
-		s.space.Remove(ent.GetCollisionComponent().CollisionShape)
-	}
-
-	delete(s.ents, e.ID())
-}
-
-func (s *ResolvSystem) AddByInterface(o ecs.Identifier) {
-	s.Add(o.(Resolvable))
-}
+package game
+
+import (
+	"image/color"
+	gomath "math"
+	"sort"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/SolarLune/resolv"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+	"github.com/sardap/walk-good-maybe-hd/math"
+)
+
+// touchBuffer is how close things have to be to count as touching
+const touchBuffer = 1
+
+type Resolvable interface {
+	ecs.BasicFace
+	components.TransformFace
+	components.IdentityFace
+	components.CollisionFace
+}
+
+type ResolvSystem struct {
+	systemOrder
+	ents map[uint64]Resolvable
+	// last is where ents were at the end of last frame
+	last           map[uint64]math.Vector2
+	space          *resolv.Space
+	overlay        *ebiten.Image
+	OverlayEnabled bool
+	debugInput     *entity.InputEnt
+}
+
+func CreateResolvSystem(space *resolv.Space, debugInput *entity.InputEnt) *ResolvSystem {
+	return &ResolvSystem{
+		space:      space,
+		debugInput: debugInput,
+	}
+}
+
+func (s *ResolvSystem) runsAfter() []ecs.System {
+	return []ecs.System{(*InputSystem)(nil), (*DumbVelocitySystem)(nil)}
+}
+
+// Collisions are handed out before VelocitySystem moves anything so it and
+// everything after it this frame sees the same ones
+func (s *ResolvSystem) runsBefore() []ecs.System {
+	return []ecs.System{(*LifeSystem)(nil), (*VelocitySystem)(nil)}
+}
+
+func (s *ResolvSystem) New(world *ecs.World) {
+	s.ents = make(map[uint64]Resolvable)
+	s.last = make(map[uint64]math.Vector2)
+	s.overlay = ebiten.NewImage(windowWidth, windowHeight)
+}
+
+func (s *ResolvSystem) Update(dt float32) {
+	if s.debugInput != nil && s.debugInput.MovementComponent.InputJustReleased(components.InputKindToggleCollsionOverlay) {
+		s.OverlayEnabled = !s.OverlayEnabled
+	}
+
+	for _, ent := range s.ents {
+		s.place(ent)
+
+		// Cleared first since swept ents can add to others
+		ent.GetCollisionComponent().Collisions = nil
+	}
+
+	for _, ent := range s.ents {
+		if !ent.GetCollisionComponent().Swept {
+			for _, hit := range s.touching(ent, touchBuffer) {
+				s.collide(ent, hit.other, hit.normal, hit.depth)
+			}
+			continue
+		}
+
+		for _, hit := range s.sweep(ent) {
+			touched := s.collide(ent, hit.other, hit.normal, hit.depth)
+
+			// Anything passed through won't see it from where it ended up so
+			// it's told here, swept ents look for themselves
+			if !hit.other.GetCollisionComponent().Swept && !overlapping(ent, hit.other, touchBuffer) {
+				s.collide(hit.other, ent, hit.normal.Mul(-1), hit.depth)
+			}
+
+			// It never got to anything after where it would have stopped
+			if touched && stopsSweep(ent, hit.other) {
+				break
+			}
+		}
+	}
+
+	for id, ent := range s.ents {
+		s.last[id] = ent.GetTransformComponent().Postion
+	}
+}
+
+// place makes sure ent has a rect in the space for each part of its hitbox
+// then lays them out
+func (s *ResolvSystem) place(ent Resolvable) {
+	colCom := ent.GetCollisionComponent()
+	rects := hitboxRects(ent)
+
+	for len(*colCom.CollisionShapes) < len(rects) {
+		colCom.CollisionShapes.Add(s.newPart(ent))
+	}
+
+	for len(*colCom.CollisionShapes) > len(rects) && len(*colCom.CollisionShapes) > 1 {
+		last := len(*colCom.CollisionShapes) - 1
+		s.space.Remove((*colCom.CollisionShapes)[last])
+		*colCom.CollisionShapes = (*colCom.CollisionShapes)[:last]
+	}
+
+	layoutHitbox(ent)
+}
+
+func (s *ResolvSystem) newPart(ent Resolvable) *resolv.Rectangle {
+	rectangle := resolv.NewRectangle(0, 0, 0, 0)
+	rectangle.AddTags(ent.GetIdentityComponent().Tags...)
+
+	// Looked up with Owner
+	rectangle.Data = ent.GetBasicEntity().ID()
+
+	s.space.Add(rectangle)
+
+	return rectangle
+}
+
+// moved is how far ent went since last frame
+func (s *ResolvSystem) moved(ent Resolvable) math.Vector2 {
+	last, ok := s.last[ent.GetBasicEntity().ID()]
+	if !ok {
+		return math.Vector2{}
+	}
+
+	return ent.GetTransformComponent().Postion.Sub(last)
+}
+
+// hit is something ent touched, when parts of both touched the pair which
+// overlapped most or were touched first in a sweep decides the normal
+type hit struct {
+	other  Resolvable
+	normal math.Vector2
+	depth  float64
+	// time is how far along a sweep it was first touched from 0 to 1
+	time float64
+	// area is how much the parts overlapped when not sweeping
+	area float64
+}
+
+// hits keeps one hit per ent in the order they were found
+type hits struct {
+	list  []hit
+	index map[uint64]int
+}
+
+// add keeps h if it's the first for its ent or better than the last one
+func (h *hits) add(newHit hit, better func(old hit) bool) {
+	if h.index == nil {
+		h.index = make(map[uint64]int)
+	}
+
+	id := newHit.other.GetBasicEntity().ID()
+	if i, ok := h.index[id]; ok {
+		if better(h.list[i]) {
+			h.list[i] = newHit
+		}
+		return
+	}
+
+	h.index[id] = len(h.list)
+	h.list = append(h.list, newHit)
+}
+
+// touching is everything within buffer of ent where it is now
+func (s *ResolvSystem) touching(ent Resolvable, buffer float64) []hit {
+	id := ent.GetBasicEntity().ID()
+	result := hits{}
+
+	for _, part := range parts(ent) {
+		for _, shape := range s.space.Collisions(grown(part, buffer)) {
+			otherPart, ok := shape.(*resolv.Rectangle)
+			if !ok {
+				continue
+			}
+
+			other, ok := s.Owner(otherPart)
+			if !ok || other.GetBasicEntity().ID() == id {
+				continue
+			}
+
+			normal, depth := contact(part, otherPart)
+			newHit := hit{
+				other:  other,
+				normal: normal,
+				depth:  depth,
+				area:   overlapArea(part, otherPart),
+			}
+			result.add(newHit, func(old hit) bool {
+				return newHit.area > old.area
+			})
+		}
+	}
+
+	return result.list
+}
+
+// overlapping is if any part of ent is within buffer of any part of other
+func overlapping(ent, other Resolvable, buffer float64) bool {
+	for _, part := range parts(ent) {
+		if other.GetCollisionComponent().CollisionShapes.IsColliding(grown(part, buffer)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sweep is everything ent touched moving from where it was last frame in the
+// order it touched them. It's worked out from how they moved compared to each
+// other so things scrolling together don't hit everything on the way
+func (s *ResolvSystem) sweep(ent Resolvable) []hit {
+	id := ent.GetBasicEntity().ID()
+	moved := s.moved(ent)
+	result := hits{}
+
+	for _, part := range parts(ent) {
+		for _, shape := range *s.space {
+			otherPart, ok := shape.(*resolv.Rectangle)
+			if !ok {
+				continue
+			}
+
+			other, ok := s.Owner(otherPart)
+			if !ok || other.GetBasicEntity().ID() == id {
+				continue
+			}
+
+			delta := moved.Sub(s.moved(other))
+			start := resolv.NewRectangle(part.X-delta.X, part.Y-delta.Y, part.W, part.H)
+			time, normal, ok := sweepRect(start, delta, otherPart)
+			if !ok {
+				continue
+			}
+
+			// Already in it so there's no side it came in through
+			if time < 0 {
+				normal, _ = contact(part, otherPart)
+			}
+
+			newHit := hit{
+				other:  other,
+				normal: normal,
+				depth:  depthAlong(part, otherPart, normal),
+				time:   gomath.Max(time, 0),
+			}
+			result.add(newHit, func(old hit) bool {
+				return newHit.time < old.time
+			})
+		}
+	}
+
+	sort.SliceStable(result.list, func(i, j int) bool {
+		return result.list[i].time < result.list[j].time
+	})
+
+	return result.list
+}
+
+// stopsSweep is if a swept ent would have stopped at other, anything solid
+// stops it and anything doing damage is used up on the first thing it hurts
+func stopsSweep(ent, other Resolvable) bool {
+	colCom := ent.GetCollisionComponent()
+	layer := other.GetCollisionComponent().CollisionLayer
+	if colCom.Blocks.Has(layer) {
+		return true
+	}
+
+	_, damages := ent.(components.DamageFace)
+	return damages && colCom.Senses.Has(layer)
+}
+
+// collide tells ent it hit other if it's on a layer ent cares about, false if
+// it didn't
+func (s *ResolvSystem) collide(ent, other Resolvable, normal math.Vector2, depth float64) bool {
+	colCom := ent.GetCollisionComponent()
+	if !colCom.Notices(other.GetCollisionComponent().CollisionLayer) {
+		return false
+	}
+
+	// Passing through a ledge isn't touching it, only standing on it is
+	if other.GetCollisionComponent().OneWay && (normal.Y >= 0 || depth > touchBuffer) {
+		return false
+	}
+
+	colCom.Collisions = append(colCom.Collisions, &components.CollisionEvent{
+		ID:     other.GetBasicEntity().ID(),
+		Tags:   other.GetCollisionComponent().CollisionShape.GetTags(),
+		Normal: normal,
+		Depth:  depth,
+	})
+
+	// Getting landed on means getting squashed not hurting them
+	if s.stomping(other, ent) {
+		return true
+	}
+
+	// apply damage
+	if damage, ok := ent.(components.DamageFace); ok {
+		damageCom := damage.GetDamageComponent()
+		amount := damageCom.BaseDamage
+		if s.stomping(ent, other) {
+			amount = damageCom.StompDamage
+		}
+
+		if other, ok := other.(components.LifeFace); ok {
+			otherLifeCom := other.GetLifeComponent()
+			otherLifeCom.DamageEvents = append(otherLifeCom.DamageEvents, &components.DamageEvent{
+				Damage: amount,
+			})
+		}
+	}
+
+	return true
+}
+
+// stomping is if ent came down onto the top of other since last frame and can
+// squash it. The side touched now can't tell a landing from walking into the
+// top corner so it's worked out from where ent was before
+func (s *ResolvSystem) stomping(ent, other Resolvable) bool {
+	damage, ok := ent.(components.DamageFace)
+	if !ok || !damage.GetDamageComponent().Stomps.Has(other.GetCollisionComponent().CollisionLayer) {
+		return false
+	}
+
+	fell := s.moved(ent).Sub(s.moved(other)).Y
+	if fell <= 0 {
+		return false
+	}
+
+	bottom := bottomOf(ent.GetCollisionComponent().CollisionShapes) - fell
+	return bottom <= topOf(other.GetCollisionComponent().CollisionShapes)+touchBuffer
+}
+
+// Entity is whatever was added with id, like the ID in a CollisionEvent
+func (s *ResolvSystem) Entity(id uint64) (Resolvable, bool) {
+	ent, ok := s.ents[id]
+	return ent, ok
+}
+
+// Owner is the entity shape in the space belongs to
+func (s *ResolvSystem) Owner(shape resolv.Shape) (Resolvable, bool) {
+	id, ok := shape.GetData().(uint64)
+	if !ok {
+		return nil, false
+	}
+
+	return s.Entity(id)
+}
+
+func (s *ResolvSystem) Render(cmds *RenderCmds) {
+	s.overlay.Fill(color.RGBA{0, 0, 0, 0})
+
+	if !s.OverlayEnabled {
+		return
+	}
+
+	for _, ent := range s.ents {
+		if !ent.GetCollisionComponent().Active {
+			continue
+		}
+
+		for _, part := range parts(ent) {
+			x1, y1, w, h := part.X, part.Y, part.W, part.H
+
+			clr := color.RGBA{255, 0, 0, 255}
+			// Left Top to Right Top
+			ebitenutil.DrawLine(s.overlay, x1, y1, x1+w, y1, clr)
+			// Right Top to Right Bottom
+			ebitenutil.DrawLine(s.overlay, x1+w, y1, x1+w, y1+h, clr)
+			// Right Bottom to Left Bottom
+			ebitenutil.DrawLine(s.overlay, x1+w, y1+h, x1, y1+h, clr)
+			// Left Bottom to Left top
+			ebitenutil.DrawLine(s.overlay, x1, y1+h, x1, y1, clr)
+		}
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	*cmds = append(*cmds, &RenderImageCmd{
+		Image:   s.overlay,
+		Options: op,
+		Layer:   ImageLayerDebug,
+	})
+}
+
+func (s *ResolvSystem) Add(r Resolvable) {
+	s.ents[r.GetBasicEntity().ID()] = r
+
+	colCom := r.GetCollisionComponent()
+	if colCom.CollisionShape != nil {
+		// A shape given up front is used as the first part
+		if !s.space.Contains(colCom.CollisionShape) {
+			s.space.Add(colCom.CollisionShape)
+		}
+		colCom.CollisionShape.Data = r.GetBasicEntity().ID()
+	} else {
+		colCom.CollisionShape = s.newPart(r)
+	}
+
+	colCom.CollisionShapes = resolv.NewSpace()
+	colCom.CollisionShapes.Add(colCom.CollisionShape)
+	s.place(r)
+}
+
+func (s *ResolvSystem) Remove(e ecs.BasicEntity) {
+	if ent, ok := s.ents[e.ID()]; ok {
+		for _, part := range parts(ent) {
+			s.space.Remove(part)
+		}
+	}
+
+	delete(s.ents, e.ID())
+	delete(s.last, e.ID())
+}
+
+func (s *ResolvSystem) AddByInterface(o ecs.Identifier) {
+	s.Add(o.(Resolvable))
+}
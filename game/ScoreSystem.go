@@ -0,0 +1,70 @@
+package game
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+)
+
+const (
+	pointsPerMetre = 10
+	pointsPerKill  = 100
+	pointsPerToken = 50
+	// maxMultiplier caps how much a streak of kills is worth
+	maxMultiplier = 5
+)
+
+// ScoreSystem keeps the scene's Stats, kills are worth more the more there
+// have been in a row without the player getting hurt
+type ScoreSystem struct {
+	systemOrder
+	mainGameScene *MainGameScene
+}
+
+func CreateScoreSystem(mainGameScene *MainGameScene) *ScoreSystem {
+	return &ScoreSystem{
+		mainGameScene: mainGameScene,
+	}
+}
+
+// Distance is from however fast the city scrolled this frame
+func (s *ScoreSystem) runsAfter() []ecs.System {
+	return []ecs.System{(*GameRuleSystem)(nil)}
+}
+
+func (s *ScoreSystem) New(world *ecs.World) {
+	events := worldEvents(world)
+	if events == nil {
+		return
+	}
+
+	events.Subscribe(EventKindTokenCollected, func(Event) {
+		s.mainGameScene.Stats.Tokens++
+		s.mainGameScene.Stats.Points += pointsPerToken
+	})
+	events.Subscribe(EventKindEntityDied, func(e Event) {
+		s.onDied(e.(EntityDied))
+	})
+	events.Subscribe(EventKindEntityDamaged, func(e Event) {
+		if _, ok := e.(EntityDamaged).Ent.(*entity.Player); ok {
+			s.mainGameScene.Stats.Combo = 0
+		}
+	})
+}
+
+func (s *ScoreSystem) onDied(e EntityDied) {
+	if !killed(e) {
+		return
+	}
+
+	stats := &s.mainGameScene.Stats
+	stats.Kills++
+	stats.Points += pointsPerKill * stats.Multiplier()
+	stats.Combo++
+}
+
+func (s *ScoreSystem) Update(dt float32) {
+	s.mainGameScene.Stats.Distance -= s.mainGameScene.ScrollingSpeed.X * float64(dt) / pixelsPerMetre
+}
+
+func (s *ScoreSystem) Remove(e ecs.BasicEntity) {
+}
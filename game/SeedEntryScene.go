@@ -0,0 +1,122 @@
+package game
+
+import (
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+	"golang.org/x/image/font"
+)
+
+const maxSeedCodeLength = 16
+
+// SeedEntryScene lets the player type in a seed code to play that exact city
+type SeedEntryScene struct {
+	Code       string
+	titleFont  font.Face
+	codeFont   font.Face
+	errMessage string
+	cursorTime time.Duration
+}
+
+func (s *SeedEntryScene) Start(game *Game) {
+	s.Code = ""
+	s.errMessage = ""
+	s.cursorTime = 0
+	s.titleFont = createFontFace(80, 72)
+	s.codeFont = createFontFace(120, 72)
+}
+
+func (s *SeedEntryScene) End(*Game) {
+	s.titleFont = nil
+	s.codeFont = nil
+}
+
+func (s *SeedEntryScene) TakesTextInput() bool {
+	return true
+}
+
+func (s *SeedEntryScene) Type(chars []rune) {
+	for _, c := range chars {
+		if len(s.Code) >= maxSeedCodeLength {
+			return
+		}
+
+		if c == ' ' || c == '-' {
+			continue
+		}
+
+		str := strings.ToUpper(string(c))
+		if _, err := utility.DecodeSeed(str); err != nil {
+			continue
+		}
+
+		s.Code += str
+		s.errMessage = ""
+	}
+}
+
+func (s *SeedEntryScene) Backspace() {
+	if len(s.Code) > 0 {
+		s.Code = s.Code[:len(s.Code)-1]
+	}
+	s.errMessage = ""
+}
+
+// Submit returns the scene to play or nil if the code is no good
+func (s *SeedEntryScene) Submit() Scene {
+	seed, err := utility.DecodeSeed(s.Code)
+	if err != nil {
+		s.errMessage = "INVALID CODE"
+		return nil
+	}
+
+	return &MainGameScene{Seed: seed}
+}
+
+func (s *SeedEntryScene) Update(dt time.Duration, game *Game) {
+	s.cursorTime += dt
+
+	s.Type(ebiten.InputChars())
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
+		s.Backspace()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		if next := s.Submit(); next != nil {
+			defer game.ChangeScene(next)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		defer game.ChangeScene(&TitleScene{})
+	}
+}
+
+func (s *SeedEntryScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{B: 255, A: 255})
+
+	drawCentered := func(str string, face font.Face, y int, clr color.Color) {
+		b := text.BoundString(face, str)
+		text.Draw(screen, str, face, windowWidth/2-b.Dx()/2, y, clr)
+	}
+
+	drawCentered("ENTER SEED", s.titleFont, 400, color.White)
+
+	code := s.Code
+	if (s.cursorTime/(500*time.Millisecond))%2 == 0 {
+		code += "_"
+	}
+	drawCentered(code, s.codeFont, 750, color.White)
+
+	if s.errMessage != "" {
+		drawCentered(s.errMessage, s.titleFont, 1000, color.RGBA{R: 255, A: 255})
+	}
+
+	drawCentered("ENTER TO PLAY  ESC TO GO BACK", s.titleFont, 1400, color.White)
+}
@@ -0,0 +1,138 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+)
+
+type Timerable interface {
+	ecs.BasicFace
+	components.TimerFace
+}
+
+type Tweenable interface {
+	ecs.BasicFace
+	components.TweenFace
+}
+
+// TimerSystem runs timers and tweens on the world thread so they stop with
+// the world when paused and go away with the scene
+type TimerSystem struct {
+	systemOrder
+	timerEnts map[uint64]Timerable
+	tweenEnts map[uint64]Tweenable
+}
+
+func CreateTimerSystem() *TimerSystem {
+	return &TimerSystem{}
+}
+
+func (s *TimerSystem) New(world *ecs.World) {
+	s.timerEnts = make(map[uint64]Timerable)
+	s.tweenEnts = make(map[uint64]Tweenable)
+}
+
+func (s *TimerSystem) Update(dt float32) {
+	delta := utility.DeltaToDuration(dt)
+
+	// Callbacks go in the order ents were made so runs play out the same way
+	for _, id := range s.sortedTimerIDs() {
+		ent, ok := s.timerEnts[id]
+		if !ok {
+			// Removed by an earlier callback
+			continue
+		}
+		timerCom := ent.GetTimerComponent()
+
+		// Callbacks can add timers so swap the list out first
+		timers := timerCom.Timers
+		timerCom.Timers = nil
+		for _, timer := range timers {
+			if timer.Stopped() {
+				continue
+			}
+
+			timer.Remaining -= delta
+			if timer.Remaining > 0 {
+				timerCom.Timers = append(timerCom.Timers, timer)
+				continue
+			}
+
+			if timer.OnDone != nil {
+				timer.OnDone()
+			}
+		}
+	}
+
+	for _, id := range s.sortedTweenIDs() {
+		ent, ok := s.tweenEnts[id]
+		if !ok {
+			continue
+		}
+		tweenCom := ent.GetTweenComponent()
+
+		tweens := tweenCom.Tweens
+		tweenCom.Tweens = nil
+		for _, tween := range tweens {
+			if tween.Stopped() {
+				continue
+			}
+
+			tween.Elapsed += delta
+			if tween.Set != nil {
+				tween.Set(tween.Value())
+			}
+
+			if !tween.Done() {
+				tweenCom.Tweens = append(tweenCom.Tweens, tween)
+				continue
+			}
+
+			if tween.OnDone != nil {
+				tween.OnDone()
+			}
+		}
+	}
+}
+
+func (s *TimerSystem) sortedTimerIDs() []uint64 {
+	ids := make([]uint64, 0, len(s.timerEnts))
+	for id := range s.timerEnts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func (s *TimerSystem) sortedTweenIDs() []uint64 {
+	ids := make([]uint64, 0, len(s.tweenEnts))
+	for id := range s.tweenEnts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func (s *TimerSystem) Add(r ecs.Identifier) {
+	if timerable, ok := r.(Timerable); ok {
+		s.timerEnts[timerable.GetBasicEntity().ID()] = timerable
+	}
+
+	if tweenable, ok := r.(Tweenable); ok {
+		s.tweenEnts[tweenable.GetBasicEntity().ID()] = tweenable
+	}
+}
+
+func (s *TimerSystem) Remove(e ecs.BasicEntity) {
+	delete(s.timerEnts, e.ID())
+	delete(s.tweenEnts, e.ID())
+}
+
+func (s *TimerSystem) AddByInterface(o ecs.Identifier) {
+	s.Add(o)
+}
@@ -1,78 +1,132 @@
-package game
-
-import (
-	"github.com/EngoEngine/ecs"
-	"github.com/SolarLune/resolv"
-	"github.com/sardap/walk-good-maybe-hd/components"
-	"github.com/sardap/walk-good-maybe-hd/entity"
-	"github.com/sardap/walk-good-maybe-hd/math"
-)
-
-type Velocityable interface {
-	ecs.BasicFace
-	components.TransformFace
-	components.IdentityFace
-	components.CollisionFace
-	components.VelocityFace
-}
-
-type VelocitySystem struct {
-	ents  map[uint64]Velocityable
-	space *resolv.Space
-}
-
-func CreateVelocitySystem(space *resolv.Space) *VelocitySystem {
-	return &VelocitySystem{
-		space: space,
-	}
-}
-
-func (s *VelocitySystem) Priority() int {
-	return int(systemPriorityVelocitySystem)
-}
-
-func (s *VelocitySystem) New(world *ecs.World) {
-	s.ents = make(map[uint64]Velocityable)
-}
-
-func (s *VelocitySystem) Update(dt float32) {
-	for _, ent := range s.ents {
-		trans := ent.GetTransformComponent()
-		colCom := ent.GetCollisionComponent()
-		vel := ent.GetVelocityComponent().Vel
-
-		vel = vel.Mul(float64(dt))
-
-		ground := s.space.FilterByTags(entity.TagGround)
-
-		collision := ground.Resolve(colCom.CollisionShape, vel.X, 0)
-		if collision.Colliding() {
-			trans.Postion.X += collision.ResolveX
-		} else {
-			trans.Postion.X += vel.X
-		}
-
-		collision = ground.Resolve(colCom.CollisionShape, 0, vel.Y)
-		if collision.Colliding() {
-			trans.Postion.Y += collision.ResolveY
-		} else {
-			trans.Postion.Y += vel.Y
-		}
-
-		colCom.CollisionShape.SetXY(trans.Postion.X, trans.Postion.Y)
-
-		ent.GetVelocityComponent().Vel = math.Vector2{}
-	}
-}
-
-func (s *VelocitySystem) Add(r Velocityable) {
-	s.ents[r.GetBasicEntity().ID()] = r
-}
-
-func (s *VelocitySystem) Remove(e ecs.BasicEntity) {
-	delete(s.ents, e.ID())
-}
-
-func (s *VelocitySystem) AddByInterface(o ecs.Identifier) {
-	s.Add(o.(Velocityable))
-}
+package game
+
+import (
+	gomath "math"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/SolarLune/resolv"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/math"
+)
+
+type Velocityable interface {
+	ecs.BasicFace
+	components.TransformFace
+	components.IdentityFace
+	components.CollisionFace
+	components.VelocityFace
+}
+
+type VelocitySystem struct {
+	systemOrder
+	ents  map[uint64]Velocityable
+	space *resolv.Space
+	world *ecs.World
+}
+
+func CreateVelocitySystem(space *resolv.Space) *VelocitySystem {
+	return &VelocitySystem{
+		space: space,
+	}
+}
+
+func (s *VelocitySystem) New(world *ecs.World) {
+	s.ents = make(map[uint64]Velocityable)
+	s.world = world
+}
+
+func (s *VelocitySystem) Update(dt float32) {
+	resolvSystem := worldResolvSystem(s.world)
+
+	for _, ent := range s.ents {
+		trans := ent.GetTransformComponent()
+		colCom := ent.GetCollisionComponent()
+		vel := ent.GetVelocityComponent().Vel
+
+		vel = vel.Mul(float64(dt))
+
+		// Ledges are never in the way going sideways
+		collision := blocking(s.space, resolvSystem, colCom, nil).Resolve(colCom.CollisionShapes, vel.X, 0)
+		if collision.Colliding() {
+			trans.Postion.X += collision.ResolveX
+		} else {
+			trans.Postion.X += vel.X
+		}
+
+		// Parts haven't been moved yet so they're still where it fell from
+		bottom := bottomOf(colCom.CollisionShapes)
+		landing := func(ledge *resolv.Rectangle) bool {
+			return vel.Y > 0 && bottom <= ledge.Y
+		}
+
+		collision = blocking(s.space, resolvSystem, colCom, landing).Resolve(colCom.CollisionShapes, 0, vel.Y)
+		if collision.Colliding() {
+			trans.Postion.Y += collision.ResolveY
+		} else {
+			trans.Postion.Y += vel.Y
+		}
+
+		layoutHitbox(ent)
+
+		ent.GetVelocityComponent().Vel = math.Vector2{}
+	}
+}
+
+// blocking is everything in space col can't move through, layers are found
+// through resolvSystem so without one nothing blocks. One way shapes only
+// block when onto says so
+func blocking(space *resolv.Space, resolvSystem *ResolvSystem, col *components.CollisionComponent, onto func(*resolv.Rectangle) bool) *resolv.Space {
+	if resolvSystem == nil {
+		return resolv.NewSpace()
+	}
+
+	return space.Filter(func(shape resolv.Shape) bool {
+		other, ok := resolvSystem.Owner(shape)
+		if !ok || !col.Blocks.Has(other.GetCollisionComponent().CollisionLayer) {
+			return false
+		}
+
+		if other.GetCollisionComponent().OneWay {
+			rect, ok := shape.(*resolv.Rectangle)
+			return ok && onto != nil && onto(rect)
+		}
+
+		return true
+	})
+}
+
+// bottomOf is the lowest edge of any of shapes
+func bottomOf(shapes *resolv.Space) float64 {
+	result := gomath.Inf(-1)
+	for _, shape := range *shapes {
+		if rect, ok := shape.(*resolv.Rectangle); ok {
+			result = gomath.Max(result, rect.Y+rect.H)
+		}
+	}
+
+	return result
+}
+
+// topOf is the highest edge of any of shapes
+func topOf(shapes *resolv.Space) float64 {
+	result := gomath.Inf(1)
+	for _, shape := range *shapes {
+		if rect, ok := shape.(*resolv.Rectangle); ok {
+			result = gomath.Min(result, rect.Y)
+		}
+	}
+
+	return result
+}
+
+func (s *VelocitySystem) Add(r Velocityable) {
+	s.ents[r.GetBasicEntity().ID()] = r
+}
+
+func (s *VelocitySystem) Remove(e ecs.BasicEntity) {
+	delete(s.ents, e.ID())
+}
+
+func (s *VelocitySystem) AddByInterface(o ecs.Identifier) {
+	s.Add(o.(Velocityable))
+}
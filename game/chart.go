@@ -0,0 +1,145 @@
+package game
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/go-mp3"
+	"github.com/sardap/walk-good-maybe-hd/components"
+)
+
+// LoadKaraokeSession reads a chart which has already been through gen
+func LoadKaraokeSession(data []byte) (*KaraokeSession, error) {
+	session := &KaraokeSession{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, err
+	}
+
+	if err := session.validate(); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *KaraokeSession) validate() error {
+	if len(s.Inputs) <= 0 {
+		return fmt.Errorf("chart has no inputs")
+	}
+
+	if len(s.Backgrounds) <= 0 {
+		return fmt.Errorf("chart has no backgrounds")
+	}
+
+	if s.Music == nil {
+		return fmt.Errorf("chart has no music")
+	}
+
+	for i, input := range s.Inputs {
+		switch input.Sound {
+		case components.KaraokeSoundA, components.KaraokeSoundB,
+			components.KaraokeSoundX, components.KaraokeSoundY:
+		default:
+			return fmt.Errorf("input %d has unknown sound %q", i, input.Sound)
+		}
+	}
+
+	return nil
+}
+
+// karaokeChart is the hand written format in assets/karaoke before gen bakes
+// the images and music into it
+type karaokeChart struct {
+	Inputs []struct {
+		Duration    int                     `json:"duration"`
+		StartOffset int                     `json:"start_off"`
+		Sound       components.KaraokeSound `json:"sound"`
+	} `json:"inputs"`
+	Backgrounds []struct {
+		Duration int    `json:"duration"`
+		FadeIn   int    `json:"fade_in"`
+		Image    string `json:"image"`
+	} `json:"backgrounds"`
+	Music string `json:"music"`
+}
+
+// LoadKaraokeChart reads a hand written chart from disk, the image and music
+// paths are relative to the chart. Only png and jpeg backgrounds work here
+// anything else needs to go through gen.
+func LoadKaraokeChart(path string) (*KaraokeSession, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	chart := karaokeChart{}
+	if err := json.Unmarshal(data, &chart); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	session := &KaraokeSession{}
+
+	// Same timing rules as gen
+	timeElapsed := 0
+	for i, input := range chart.Inputs {
+		startTime := 0
+		if i > 0 {
+			startTime = timeElapsed + chart.Inputs[i-1].Duration + input.StartOffset
+			timeElapsed += input.Duration
+		}
+
+		session.Inputs = append(session.Inputs, &KaraokeInput{
+			StartTime: DurationMil(time.Duration(startTime) * time.Millisecond),
+			Duration:  DurationMil(time.Duration(input.Duration) * time.Millisecond),
+			Sound:     input.Sound,
+		})
+	}
+
+	for i, background := range chart.Backgrounds {
+		raw, err := ioutil.ReadFile(filepath.Join(dir, background.Image))
+		if err != nil {
+			return nil, fmt.Errorf("background %d: %w", i, err)
+		}
+
+		if _, _, err := image.DecodeConfig(bytes.NewReader(raw)); err != nil {
+			return nil, fmt.Errorf("background %d %s: %w", i, background.Image, err)
+		}
+
+		session.Backgrounds = append(session.Backgrounds, &KaraokeBackground{
+			Duration: DurationMil(time.Duration(background.Duration) * time.Millisecond),
+			FadeIn:   DurationMil(time.Duration(background.FadeIn) * time.Millisecond),
+			Image:    base64.StdEncoding.EncodeToString(raw),
+		})
+	}
+
+	if chart.Music == "" {
+		return nil, fmt.Errorf("%s: chart has no music", path)
+	}
+
+	rawMusic, err := ioutil.ReadFile(filepath.Join(dir, chart.Music))
+	if err != nil {
+		return nil, fmt.Errorf("music: %w", err)
+	}
+
+	decoder, err := mp3.NewDecoder(bytes.NewReader(rawMusic))
+	if err != nil {
+		return nil, fmt.Errorf("music %s: %w", chart.Music, err)
+	}
+	session.SampleRate = decoder.SampleRate()
+
+	music := base64.StdEncoding.EncodeToString(rawMusic)
+	session.Music = &music
+
+	if err := session.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return session, nil
+}
@@ -0,0 +1,492 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/sardap/walk-good-maybe-hd/assets"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+	"golang.org/x/image/font"
+)
+
+const (
+	consoleToggleKey  = ebiten.KeyGraveAccent
+	consoleMaxLines   = 20
+	consoleLineHeight = 50
+)
+
+// Console is the developer overlay opened with ` it's drawn over everything
+// including ImageLayerDebug and pauses the scene while it's open
+type Console struct {
+	Open  bool
+	Input string
+	// Output newest last
+	Output     []string
+	font       font.Face
+	cursorTime time.Duration
+}
+
+type consoleCommand struct {
+	usage string
+	run   func(g *Game, args []string) (string, error)
+}
+
+var errConsoleNoMainGame = fmt.Errorf("only works in the main game")
+
+var consoleCommands = map[string]consoleCommand{
+	"spawn": {
+		usage: "spawn <prefab> [x y] puts a prefab at the cursor",
+		run:   consoleSpawn,
+	},
+	"gravity": {
+		usage: "gravity <n>",
+		run:   consoleGravity,
+	},
+	"scroll": {
+		usage: "scroll <x> [y] sets the scrolling speed",
+		run:   consoleScroll,
+	},
+	"hp": {
+		usage: "hp <n> sets the player's hp",
+		run:   consoleHp,
+	},
+	"give": {
+		usage: "give <jump|speed> gives the player a token",
+		run:   consoleGive,
+	},
+	"collision": {
+		usage: "collision toggles the collision overlay",
+		run:   consoleCollision,
+	},
+	"scene": {
+		usage: "scene <title|main|karaoke|seed|options|scores>",
+		run:   consoleScene,
+	},
+	"inspect": {
+		usage: "inspect <id> shows the entity in the inspector",
+		run:   consoleInspect,
+	},
+	"profile": {
+		usage: "profile [save <path.csv|path.json>] toggles the profiler",
+		run:   consoleProfile,
+	},
+	"ents": {
+		usage: "ents <tag> lists entities with the tag",
+		run:   consoleEnts,
+	},
+}
+
+func (c *Console) Toggle() {
+	c.Open = !c.Open
+	c.Input = ""
+}
+
+func (c *Console) Type(chars []rune) {
+	for _, char := range chars {
+		// The toggle key comes through as a char on the frame it's pressed
+		if char == '`' || char == '~' {
+			continue
+		}
+		c.Input += string(char)
+	}
+}
+
+func (c *Console) Backspace() {
+	if len(c.Input) > 0 {
+		c.Input = c.Input[:len(c.Input)-1]
+	}
+}
+
+func (c *Console) print(line string) {
+	c.Output = append(c.Output, strings.Split(line, "\n")...)
+	if len(c.Output) > consoleMaxLines {
+		c.Output = c.Output[len(c.Output)-consoleMaxLines:]
+	}
+}
+
+// Submit runs whatever has been typed
+func (c *Console) Submit(g *Game) {
+	line := c.Input
+	c.Input = ""
+	c.print("> " + line)
+
+	result, err := c.Exec(g, line)
+	if err != nil {
+		c.print("error: " + err.Error())
+	} else if result != "" {
+		c.print(result)
+	}
+}
+
+func (c *Console) Exec(g *Game, line string) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	switch fields[0] {
+	case "help":
+		var names []string
+		for name := range consoleCommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var lines []string
+		for _, name := range names {
+			lines = append(lines, consoleCommands[name].usage)
+		}
+		return strings.Join(lines, "\n"), nil
+	case "clear":
+		c.Output = nil
+		return "", nil
+	}
+
+	cmd, ok := consoleCommands[fields[0]]
+	if !ok {
+		return "", fmt.Errorf("unknown command %q try help", fields[0])
+	}
+
+	return cmd.run(g, fields[1:])
+}
+
+func (c *Console) Update(dt time.Duration, g *Game) {
+	c.cursorTime += dt
+
+	c.Type(ebiten.InputChars())
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) {
+		c.Backspace()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		c.Submit(g)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		c.Toggle()
+	}
+}
+
+func (c *Console) Draw(screen *ebiten.Image, g *Game) {
+	if c.font == nil {
+		c.font = createFontFace(40, 72)
+	}
+
+	height := float64((consoleMaxLines + 2) * consoleLineHeight)
+	ebitenutil.DrawRect(screen, 0, 0, windowWidth, height, color.RGBA{A: 200})
+
+	fps := fmt.Sprintf("FPS %2.f TPS %d", ebiten.CurrentFPS(), g.TickRate())
+	b := text.BoundString(c.font, fps)
+	text.Draw(screen, fps, c.font, windowWidth-b.Dx()-20, consoleLineHeight, color.White)
+
+	for i, line := range c.Output {
+		text.Draw(screen, line, c.font, 20, (i+1)*consoleLineHeight, color.White)
+	}
+
+	input := "> " + c.Input
+	if (c.cursorTime/(500*time.Millisecond))%2 == 0 {
+		input += "_"
+	}
+	text.Draw(screen, input, c.font, 20, int(height)-consoleLineHeight/2, color.RGBA{G: 255, A: 255})
+}
+
+// mainGameScene is the main game on the stack even if something like the
+// pause scene is on top of it
+func (g *Game) mainGameScene() *MainGameScene {
+	stack := append(append([]Scene{}, g.covered...), g.current)
+	for i := len(stack) - 1; i >= 0; i-- {
+		if m, ok := stack[i].(*MainGameScene); ok {
+			return m
+		}
+	}
+
+	return nil
+}
+
+func worldResolvSystem(w *ecs.World) *ResolvSystem {
+	for _, system := range w.Systems() {
+		if resolvSystem, ok := system.(*ResolvSystem); ok {
+			return resolvSystem
+		}
+	}
+
+	return nil
+}
+
+func worldInspectorSystem(w *ecs.World) *InspectorSystem {
+	for _, system := range w.Systems() {
+		if inspectorSystem, ok := system.(*InspectorSystem); ok {
+			return inspectorSystem
+		}
+	}
+
+	return nil
+}
+
+func consoleFloats(args []string, min, max int) ([]float64, error) {
+	if len(args) < min || len(args) > max {
+		return nil, fmt.Errorf("expected %d to %d numbers got %d", min, max, len(args))
+	}
+
+	result := make([]float64, len(args))
+	for i, arg := range args {
+		value, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q isn't a number", arg)
+		}
+		result[i] = value
+	}
+
+	return result, nil
+}
+
+func consoleSpawn(g *Game, args []string) (string, error) {
+	m := g.mainGameScene()
+	if m == nil {
+		return "", errConsoleNoMainGame
+	}
+
+	if len(args) != 1 && len(args) != 3 {
+		return "", fmt.Errorf("expected a prefab and maybe x y")
+	}
+
+	x, y := ebiten.CursorPosition()
+	pos := []float64{float64(x), float64(y)}
+	if len(args) == 3 {
+		var err error
+		pos, err = consoleFloats(args[1:], 2, 2)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	ent, err := entity.DefaultPrefabs().Instantiate(m.World, args[0], func(ent ecs.Identifier) {
+		trans := ent.(components.TransformFace).GetTransformComponent()
+		trans.Postion.X = pos[0]
+		trans.Postion.Y = pos[1]
+		setImageLayer(ent, ImageLayerObjects)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("spawned %s %d at %.f,%.f", args[0], ent.ID(), pos[0], pos[1]), nil
+}
+
+func consoleGravity(g *Game, args []string) (string, error) {
+	m := g.mainGameScene()
+	if m == nil {
+		return "", errConsoleNoMainGame
+	}
+
+	values, err := consoleFloats(args, 1, 1)
+	if err != nil {
+		return "", err
+	}
+	m.Gravity = values[0]
+
+	return fmt.Sprintf("gravity %v", m.Gravity), nil
+}
+
+func consoleScroll(g *Game, args []string) (string, error) {
+	m := g.mainGameScene()
+	if m == nil {
+		return "", errConsoleNoMainGame
+	}
+
+	values, err := consoleFloats(args, 1, 2)
+	if err != nil {
+		return "", err
+	}
+	m.ScrollingSpeed.X = values[0]
+	if len(values) > 1 {
+		m.ScrollingSpeed.Y = values[1]
+	}
+
+	return fmt.Sprintf("scrolling %v,%v", m.ScrollingSpeed.X, m.ScrollingSpeed.Y), nil
+}
+
+func consoleHp(g *Game, args []string) (string, error) {
+	m := g.mainGameScene()
+	if m == nil || m.Player == nil {
+		return "", errConsoleNoMainGame
+	}
+
+	values, err := consoleFloats(args, 1, 1)
+	if err != nil {
+		return "", err
+	}
+	m.Player.HP = values[0]
+
+	return fmt.Sprintf("hp %v", m.Player.HP), nil
+}
+
+// consoleGive makes it look like the player touched a token so it goes
+// through the same path as picking one up
+func consoleGive(g *Game, args []string) (string, error) {
+	m := g.mainGameScene()
+	if m == nil || m.Player == nil {
+		return "", errConsoleNoMainGame
+	}
+
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected jump or speed")
+	}
+
+	var tag int
+	switch args[0] {
+	case "jump":
+		tag = entity.TagJumpToken
+	case "speed":
+		tag = entity.TagSpeedToken
+	default:
+		return "", fmt.Errorf("unknown token %q", args[0])
+	}
+
+	m.Player.Collisions = append(m.Player.Collisions, &components.CollisionEvent{Tags: []int{tag}})
+
+	return fmt.Sprintf("gave %s token", args[0]), nil
+}
+
+func consoleCollision(g *Game, args []string) (string, error) {
+	m := g.mainGameScene()
+	if m == nil {
+		return "", errConsoleNoMainGame
+	}
+
+	resolvSystem := worldResolvSystem(m.World)
+	if resolvSystem == nil {
+		return "", fmt.Errorf("no collision system")
+	}
+	resolvSystem.OverlayEnabled = !resolvSystem.OverlayEnabled
+
+	return fmt.Sprintf("collision overlay %v", resolvSystem.OverlayEnabled), nil
+}
+
+func consoleScene(g *Game, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected a scene")
+	}
+
+	var scene Scene
+	switch args[0] {
+	case "title":
+		scene = &TitleScene{}
+	case "main":
+		scene = &MainGameScene{}
+	case "karaoke":
+		session, err := LoadKaraokeSession(assets.LoadKaraoke(assets.KaraokeTest))
+		if err != nil {
+			return "", err
+		}
+		scene = &KaraokeScene{Session: session}
+	case "seed":
+		scene = &SeedEntryScene{}
+	case "options":
+		scene = &OptionsScene{}
+	case "scores":
+		scene = &LeaderboardScene{}
+	default:
+		return "", fmt.Errorf("unknown scene %q", args[0])
+	}
+
+	g.ChangeSceneWith(scene, Transition{Type: TransitionNone})
+
+	return "scene " + args[0], nil
+}
+
+func consoleEnts(g *Game, args []string) (string, error) {
+	m := g.mainGameScene()
+	if m == nil {
+		return "", errConsoleNoMainGame
+	}
+
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected a tag")
+	}
+
+	tag, ok := entity.TagByName(args[0])
+	if !ok {
+		return "", fmt.Errorf("unknown tag %q", args[0])
+	}
+
+	resolvSystem := worldResolvSystem(m.World)
+	if resolvSystem == nil {
+		return "", fmt.Errorf("no collision system")
+	}
+
+	var ids []uint64
+	for id, ent := range resolvSystem.ents {
+		for _, entTag := range ent.GetIdentityComponent().Tags {
+			if entTag == tag {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	lines := []string{fmt.Sprintf("%d %s", len(ids), args[0])}
+	for _, id := range ids {
+		trans := resolvSystem.ents[id].GetTransformComponent()
+		lines = append(lines, fmt.Sprintf("%d at %.f,%.f", id, trans.Postion.X, trans.Postion.Y))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func consoleProfile(g *Game, args []string) (string, error) {
+	if len(args) == 0 {
+		g.Profiler().SetEnabled(!g.Profiler().Enabled)
+		return fmt.Sprintf("profiler %v", g.Profiler().Enabled), nil
+	}
+
+	if len(args) != 2 || args[0] != "save" {
+		return "", fmt.Errorf("expected save and a path")
+	}
+
+	if err := g.Profiler().Save(args[1]); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("saved %d samples to %s", len(g.Profiler().Samples()), args[1]), nil
+}
+
+func consoleInspect(g *Game, args []string) (string, error) {
+	m := g.mainGameScene()
+	if m == nil {
+		return "", errConsoleNoMainGame
+	}
+
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected an entity id")
+	}
+
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%q isn't an id", args[0])
+	}
+
+	inspectorSystem := worldInspectorSystem(m.World)
+	if inspectorSystem == nil {
+		return "", fmt.Errorf("no inspector")
+	}
+
+	if !inspectorSystem.Select(id) {
+		return "", fmt.Errorf("no entity %d", id)
+	}
+	inspectorSystem.Enabled = true
+
+	return fmt.Sprintf("inspecting %d", id), nil
+}
@@ -1,18 +1,32 @@
-package game
-
-import "image/color"
-
-const (
-	scaleMultiplier          = 10
-	windowWidth              = 240 * scaleMultiplier
-	windowHeight             = 160 * scaleMultiplier
-	xStartScrollSpeed        = -100.5
-	startingGravity          = 500
-	minSpaceBetweenBuildings = 30 * scaleMultiplier
-)
-
-var (
-	swapColor = color.RGBA{
-		R: 255, G: 0, B: 247, A: 255,
-	}
-)
+package game
+
+import "image/color"
+
+// The size of the game before it's scaled up
+const (
+	NativeWidth  = 240
+	NativeHeight = 160
+)
+
+const (
+	defaultWindowWidth  = 1280
+	defaultWindowHeight = 720
+)
+
+const (
+	scaleMultiplier          = 10
+	windowWidth              = NativeWidth * scaleMultiplier
+	windowHeight             = NativeHeight * scaleMultiplier
+	xStartScrollSpeed        = -100.5
+	startingGravity          = 500
+	minSpaceBetweenBuildings = 30 * scaleMultiplier
+	DefaultTickRate          = 60
+	MaxTickRate              = 1000
+	maxTicksPerUpdate        = 5
+)
+
+var (
+	swapColor = color.RGBA{
+		R: 255, G: 0, B: 247, A: 255,
+	}
+)
@@ -0,0 +1,133 @@
+package game
+
+import (
+	"github.com/EngoEngine/ecs"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+)
+
+type EventKind int
+
+const (
+	EventKindTokenCollected EventKind = iota
+	EventKindEntityDamaged
+	EventKindEntityDied
+	EventKindPlayerLanded
+	EventKindBulletFired
+	EventKindEnemyStomped
+)
+
+type Event interface {
+	Kind() EventKind
+}
+
+// TokenCollected is sent when the player touches a token, Tag is either
+// entity.TagJumpToken or entity.TagSpeedToken
+type TokenCollected struct {
+	Player *entity.Player
+	Tag    int
+}
+
+func (TokenCollected) Kind() EventKind {
+	return EventKindTokenCollected
+}
+
+type EntityDamaged struct {
+	Ent    Lifeable
+	Damage float64
+}
+
+func (EntityDamaged) Kind() EventKind {
+	return EventKindEntityDamaged
+}
+
+// EntityDied is sent once an entity runs out of HP, it's already been
+// removed from the world by the time anyone gets it
+type EntityDied struct {
+	Ent Lifeable
+}
+
+func (EntityDied) Kind() EventKind {
+	return EventKindEntityDied
+}
+
+type PlayerLanded struct {
+	Player *entity.Player
+}
+
+func (PlayerLanded) Kind() EventKind {
+	return EventKindPlayerLanded
+}
+
+type BulletFired struct {
+	Bullet  *entity.Bullet
+	Shooter ecs.Identifier
+}
+
+func (BulletFired) Kind() EventKind {
+	return EventKindBulletFired
+}
+
+// EnemyStomped is sent when the player lands on top of an enemy, Enemy is its
+// ID since it's likely dead by the time anyone gets it
+type EnemyStomped struct {
+	Player *entity.Player
+	Enemy  uint64
+}
+
+func (EnemyStomped) Kind() EventKind {
+	return EventKindEnemyStomped
+}
+
+type EventHandler func(Event)
+
+// EventBus queues events as they are published and hands them out when the
+// world's EventSystem runs which is after every other system
+type EventBus struct {
+	handlers map[EventKind][]EventHandler
+	queue    []Event
+}
+
+func CreateEventBus() *EventBus {
+	return &EventBus{
+		handlers: make(map[EventKind][]EventHandler),
+	}
+}
+
+func (b *EventBus) Subscribe(kind EventKind, handler EventHandler) {
+	b.handlers[kind] = append(b.handlers[kind], handler)
+}
+
+// Publish does nothing on a nil bus so systems work in worlds without one
+func (b *EventBus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+
+	b.queue = append(b.queue, event)
+}
+
+// Dispatch sends everything queued in the order it was published, events
+// published by handlers get sent in the same dispatch
+func (b *EventBus) Dispatch() {
+	for len(b.queue) > 0 {
+		queue := b.queue
+		b.queue = nil
+		for _, event := range queue {
+			for _, handler := range b.handlers[event.Kind()] {
+				handler(event)
+			}
+		}
+	}
+}
+
+// worldEvents finds the bus belonging to the world, the EventSystem has to
+// be added before any system which calls this in New
+func worldEvents(world *ecs.World) *EventBus {
+	for _, system := range world.Systems() {
+		if eventSystem, ok := system.(*EventSystem); ok {
+			return eventSystem.bus
+		}
+	}
+
+	return nil
+}
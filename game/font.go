@@ -0,0 +1,33 @@
+package game
+
+import (
+	"log"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+)
+
+var (
+	mplusFont     *opentype.Font
+	mplusFontOnce sync.Once
+)
+
+func createFontFace(size, dpi float64) font.Face {
+	mplusFontOnce.Do(func() {
+		var err error
+		mplusFont, err = opentype.Parse(fonts.MPlus1pRegular_ttf)
+		if err != nil {
+			log.Fatal(err)
+		}
+	})
+
+	face, _ := opentype.NewFace(mplusFont, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     dpi,
+		Hinting: font.HintingFull,
+	})
+
+	return face
+}
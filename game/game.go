@@ -1,92 +1,401 @@
-package game
-
-import (
-	"fmt"
-	"image/color"
-	"time"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/sardap/walk-good-maybe-hd/components"
-)
-
-const (
-	ImageLayerBottom components.ImageLayer = iota
-	ImageLayerCityLayer
-	ImageLayercityFogLayer
-	ImageLayerObjects
-	ImagelayerToken
-	ImageLayerbullet
-	ImageLayerbuildingForground
-	ImageLayerUi
-	ImageLayerDebug
-)
-
-type Scene interface {
-	Start(*Game)
-	End(*Game)
-	Update(dt time.Duration, game *Game)
-	Draw(screen *ebiten.Image)
-}
-
-type Game struct {
-	audioCtx *audio.Context
-	lastTime time.Time
-	Info     *Info
-	current  Scene
-}
-
-func (g *Game) ChangeScene(newScene Scene) {
-	if g.current != nil {
-		g.current.End(g)
-	}
-	g.current = newScene
-	g.current.Start(g)
-	g.lastTime = time.Unix(0, 0)
-}
-
-func CreateGame() *Game {
-	result := &Game{
-		audioCtx: audio.NewContext(48000),
-	}
-
-	return result
-}
-
-func (g *Game) Update() error {
-	if g.current == nil {
-		g.ChangeScene(&TitleScene{})
-	}
-	if g.lastTime.Unix() == 0 {
-		g.lastTime = time.Now()
-	}
-
-	dt := time.Since(g.lastTime)
-	g.current.Update(dt, g)
-	g.lastTime = time.Now()
-
-	if inpututil.IsKeyJustReleased(ebiten.KeyR) {
-		g.ChangeScene(&TitleScene{})
-	}
-
-	return nil
-}
-
-func (g *Game) Draw(screen *ebiten.Image) {
-
-	screen.Fill(color.White)
-	g.current.Draw(screen)
-
-	img := ebiten.NewImage(50, 50)
-	ebitenutil.DebugPrint(img, fmt.Sprintf("%2.f", ebiten.CurrentFPS()))
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Scale(10, 10)
-	op.GeoM.Translate(0, 0)
-	screen.DrawImage(img, op)
-}
-
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return windowWidth, windowHeight
-}
+package game
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/scores"
+	"github.com/sardap/walk-good-maybe-hd/settings"
+)
+
+const (
+	ImageLayerBottom components.ImageLayer = iota
+	ImageLayerCityLayer
+	ImageLayercityFogLayer
+	ImageLayerObjects
+	ImagelayerToken
+	ImageLayerbullet
+	ImageLayerbuildingForground
+	ImageLayerUi
+	ImageLayerDebug
+)
+
+type Scene interface {
+	Start(*Game)
+	End(*Game)
+	Update(dt time.Duration, game *Game)
+	Draw(screen *ebiten.Image)
+}
+
+// Scenes which take typed text stop the global hotkeys from firing
+type textEntryScene interface {
+	TakesTextInput() bool
+}
+
+// Scenes which are drawn over the top of the scene they were pushed on
+type overlayScene interface {
+	Transparent() bool
+}
+
+// Scenes which want to know when another scene is pushed on top of them
+type coverableScene interface {
+	Covered(*Game)
+	Uncovered(*Game)
+}
+
+type Game struct {
+	audioCtx    *audio.Context
+	lastTime    time.Time
+	accumulator time.Duration
+	tickRate    int
+	Info        *Info
+	current     Scene
+	// Scenes under current oldest first, these don't get updated
+	covered    []Scene
+	transition *transitionState
+	startScene Scene
+	muted      bool
+	settings   *settings.Settings
+	// settingsPath is where SaveSettings writes to, empty means don't
+	settingsPath string
+	scores       scores.Storage
+	console      Console
+}
+
+// ChangeScene ends every scene on the stack and starts the new one using the
+// transition the new scene asks for
+func (g *Game) ChangeScene(newScene Scene) {
+	transition := defaultTransition
+	if requester, ok := newScene.(transitionScene); ok {
+		transition = requester.Transition()
+	}
+
+	g.ChangeSceneWith(newScene, transition)
+}
+
+// ChangeSceneWith is ChangeScene with a specific transition
+func (g *Game) ChangeSceneWith(newScene Scene, transition Transition) {
+	if g.transition != nil {
+		g.transition.dispose()
+		g.transition = nil
+	}
+
+	// Old scenes are gone once ended so keep what they looked like
+	if g.current != nil && transition.Type != TransitionNone && transition.Duration > 0 {
+		from := ebiten.NewImage(windowWidth, windowHeight)
+		g.drawScenes(from)
+		g.transition = &transitionState{
+			Transition: transition,
+			from:       from,
+		}
+	}
+
+	if g.current != nil {
+		g.current.End(g)
+	}
+	for i := len(g.covered) - 1; i >= 0; i-- {
+		g.covered[i].End(g)
+	}
+	g.covered = nil
+
+	g.current = newScene
+	g.beginScene(newScene)
+	g.lastTime = time.Unix(0, 0)
+	g.accumulator = 0
+}
+
+// beginScene is timed since it's where most assets get decoded
+func (g *Game) beginScene(scene Scene) {
+	profiler.Measure(typeName(scene)+".Start", func() {
+		scene.Start(g)
+	})
+}
+
+// PushScene starts the new scene on top of the current one which is kept
+// around untouched until the new scene is popped
+func (g *Game) PushScene(newScene Scene) {
+	if g.current != nil {
+		if coverable, ok := g.current.(coverableScene); ok {
+			coverable.Covered(g)
+		}
+		g.covered = append(g.covered, g.current)
+	}
+
+	g.current = newScene
+	g.beginScene(newScene)
+	g.accumulator = 0
+}
+
+// PopScene ends the current scene and resumes the one below it
+func (g *Game) PopScene() {
+	if len(g.covered) == 0 {
+		panic("no scene to pop back to")
+	}
+
+	g.current.End(g)
+	g.current = g.covered[len(g.covered)-1]
+	g.covered = g.covered[:len(g.covered)-1]
+	g.accumulator = 0
+
+	if coverable, ok := g.current.(coverableScene); ok {
+		coverable.Uncovered(g)
+	}
+}
+
+// Close ends every scene on the stack, it's for once the game has stopped
+// running so scenes still get a chance to save
+func (g *Game) Close() {
+	if g.current != nil {
+		g.current.End(g)
+	}
+	for i := len(g.covered) - 1; i >= 0; i-- {
+		g.covered[i].End(g)
+	}
+
+	g.current = nil
+	g.covered = nil
+}
+
+// Transitioning is true while the last scene change is still animating
+func (g *Game) Transitioning() bool {
+	return g.transition != nil
+}
+
+// SceneDepth is how many scenes are on the stack including the current one
+func (g *Game) SceneDepth() int {
+	if g.current == nil {
+		return 0
+	}
+
+	return len(g.covered) + 1
+}
+
+// visibleScenes returns the scenes that need drawing bottom first
+func (g *Game) visibleScenes() []Scene {
+	stack := append(append([]Scene{}, g.covered...), g.current)
+
+	start := len(stack) - 1
+	for start > 0 {
+		overlay, ok := stack[start].(overlayScene)
+		if !ok || !overlay.Transparent() {
+			break
+		}
+		start--
+	}
+
+	return stack[start:]
+}
+
+func CreateGame() *Game {
+	result := &Game{
+		audioCtx: audio.NewContext(48000),
+		tickRate: DefaultTickRate,
+	}
+
+	return result
+}
+
+// SetStartScene picks what is shown first instead of the title
+func (g *Game) SetStartScene(scene Scene) {
+	g.startScene = scene
+}
+
+func (g *Game) SetMuted(muted bool) {
+	g.muted = muted
+}
+
+// Volume is what scenes should set their sound systems to
+func (g *Game) Volume() float64 {
+	if g.muted {
+		return 0
+	}
+
+	return g.Settings().Volume
+}
+
+func (g *Game) Settings() *settings.Settings {
+	if g.settings == nil {
+		g.settings = settings.Default()
+	}
+
+	return g.settings
+}
+
+// SetSettings replaces the settings and applies the window ones straight away
+// everything else is picked up when the next scene starts
+func (g *Game) SetSettings(s *settings.Settings, path string) {
+	g.settings = s
+	g.settingsPath = path
+	g.applyWindowSettings()
+}
+
+// SaveSettings applies the settings and writes them to the settings file
+func (g *Game) SaveSettings(s *settings.Settings) error {
+	g.SetSettings(s, g.settingsPath)
+	if g.settingsPath == "" {
+		return nil
+	}
+
+	return settings.SaveFile(g.settingsPath, s)
+}
+
+// Scores is where the high scores are kept, they only last until the game
+// closes unless SetScores is given somewhere better
+func (g *Game) Scores() scores.Storage {
+	if g.scores == nil {
+		g.scores = &scores.MemoryStorage{}
+	}
+
+	return g.scores
+}
+
+func (g *Game) SetScores(storage scores.Storage) {
+	g.scores = storage
+}
+
+func (g *Game) applyWindowSettings() {
+	s := g.Settings()
+
+	ebiten.SetFullscreen(s.Fullscreen)
+	if s.WindowScale > 0 {
+		ebiten.SetWindowSize(NativeWidth*s.WindowScale, NativeHeight*s.WindowScale)
+	} else {
+		ebiten.SetWindowSize(defaultWindowWidth, defaultWindowHeight)
+	}
+}
+
+// applyInputSettings sets the bindings from the settings on an input
+func (g *Game) applyInputSettings(com *components.InputComponent) {
+	g.Settings().ApplyMapping(com)
+}
+
+// Profiler is shared by every game since scenes can't see the game when drawing
+func (g *Game) Profiler() *Profiler {
+	return profiler
+}
+
+// TickRate is how many times a second the current scene is updated
+func (g *Game) TickRate() int {
+	if g.tickRate <= 0 {
+		return DefaultTickRate
+	}
+
+	return g.tickRate
+}
+
+func (g *Game) SetTickRate(tickRate int) {
+	g.tickRate = tickRate
+	g.accumulator = 0
+}
+
+func (g *Game) TickDuration() time.Duration {
+	return time.Second / time.Duration(g.TickRate())
+}
+
+// Advance feeds elapsed wall clock time into the accumulator and steps the
+// current scene in fixed ticks. Anything past maxTicksPerUpdate is dropped so a
+// stalled frame can't snowball into a spiral of catch up ticks.
+func (g *Game) Advance(elapsed time.Duration) (ticks int) {
+	tick := g.TickDuration()
+
+	g.accumulator += elapsed
+	if g.accumulator > tick*maxTicksPerUpdate {
+		g.accumulator = tick * maxTicksPerUpdate
+	}
+
+	for g.accumulator >= tick {
+		g.accumulator -= tick
+		ticks++
+
+		if g.transition != nil {
+			g.transition.elapsed += tick
+			if g.transition.Done() {
+				g.transition.dispose()
+				g.transition = nil
+			}
+		}
+
+		scene := g.current
+		scene.Update(tick, g)
+		// Scene changed mid step so the rest of the time belonged to the old one
+		if scene != g.current {
+			break
+		}
+	}
+
+	return
+}
+
+func (g *Game) Update() error {
+	profiler.NextFrame()
+
+	if g.current == nil {
+		if g.startScene != nil {
+			g.ChangeScene(g.startScene)
+		} else {
+			g.ChangeScene(&TitleScene{})
+		}
+	}
+
+	now := time.Now()
+	if g.lastTime.Unix() == 0 || g.lastTime.IsZero() {
+		g.lastTime = now
+	}
+
+	if inpututil.IsKeyJustPressed(consoleToggleKey) {
+		g.console.Toggle()
+	}
+
+	// The scene is paused while typing so keys don't leak into it
+	if g.console.Open {
+		g.console.Update(now.Sub(g.lastTime), g)
+		g.lastTime = now
+		return nil
+	}
+
+	profiler.Measure("Game.Update", func() {
+		g.Advance(now.Sub(g.lastTime))
+	})
+	g.lastTime = now
+
+	if typing, ok := g.current.(textEntryScene); ok && typing.TakesTextInput() {
+		return nil
+	}
+
+	if inpututil.IsKeyJustReleased(ebiten.KeyR) {
+		g.ChangeScene(&TitleScene{})
+	}
+
+	return nil
+}
+
+func (g *Game) drawScenes(screen *ebiten.Image) {
+	screen.Fill(color.White)
+	for _, scene := range g.visibleScenes() {
+		scene.Draw(screen)
+	}
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	profiler.Measure("Game.Draw", func() {
+		if g.transition != nil {
+			g.transition.Draw(screen, g.drawScenes)
+		} else {
+			g.drawScenes(screen)
+		}
+	})
+
+	profiler.Draw(screen)
+
+	if g.console.Open {
+		g.console.Draw(screen, g)
+	}
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return windowWidth, windowHeight
+}
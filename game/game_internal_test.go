@@ -1,552 +1,1928 @@
-package game
-
-import (
-	"encoding/base64"
-	"image/color"
-	gomath "math"
-	"math/rand"
-	"testing"
-	"time"
-
-	"github.com/EngoEngine/ecs"
-	"github.com/SolarLune/resolv"
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/sardap/walk-good-maybe-hd/assets"
-	"github.com/sardap/walk-good-maybe-hd/components"
-	"github.com/sardap/walk-good-maybe-hd/entity"
-	"github.com/sardap/walk-good-maybe-hd/math"
-	"github.com/stretchr/testify/assert"
-)
-
-func emptyImage(img *ebiten.Image) bool {
-	for y := 0; y < img.Bounds().Dy(); y++ {
-		for x := 0; x < img.Bounds().Dx(); x++ {
-			if img.At(x, y) != color.White {
-				return false
-			}
-		}
-	}
-
-	return true
-}
-
-type fakeRand struct {
-	seq []int64
-	top int
-}
-
-func (f *fakeRand) Int63() int64 {
-	if f.top >= len(f.seq) {
-		return rand.Int63()
-	}
-	result := f.seq[f.top]
-	f.top++
-	return result
-}
-
-func (*fakeRand) Seed(seed int64) {
-}
-
-func findSeed(min, max float64) (seed int64, num float64) {
-	for {
-		seed = rand.Int63()
-		num = rand.New(&fakeRand{seq: []int64{seed}}).Float64()
-		if num >= min && num <= max {
-			return
-		}
-	}
-}
-
-func TestCreateAllBuildings(t *testing.T) {
-	t.Parallel()
-
-	s := resolv.NewSpace()
-	mainGameScene := &MainGameScene{
-		Level: &Level{},
-		Space: s,
-	}
-
-	// Building 5 is non standard shape
-	testCases := []struct {
-		name string
-		seq  []int64
-	}{
-		{name: "building 0"},
-		{name: "building 1"},
-		{name: "building 2"},
-		{name: "building 3"},
-		{name: "building 4"},
-		{name: "building 5"},
-	}
-
-	for i, testcase := range testCases {
-		step := 1.0 / float64(len(testCases))
-		max := step * float64(i+1)
-		seed, _ := findSeed(max-step, max)
-
-		mainGameScene.Rand = rand.New(&fakeRand{seq: []int64{seed}})
-
-		LevelBlock := createRandomLevelBlock(mainGameScene.Rand, ecs.NewBasic())
-		expected := LevelBlock.TileMap.TileWidth * LevelBlock.TileMap.TileXNum
-		assert.Equalf(t, expected, int(LevelBlock.Size.X), "invalid tileWidth for %s", testcase.name)
-
-		for _, tile := range LevelBlock.TileMap.Map {
-			assert.GreaterOrEqualf(t, tile, int16(0), "invalid greater or equal %s", testcase.name)
-		}
-	}
-}
-
-func TestCityLevelGenerate(t *testing.T) {
-	t.Parallel()
-
-	w := &ecs.World{}
-	s := resolv.NewSpace()
-	mainGameScene := &MainGameScene{
-		Level: &Level{},
-		Rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
-		Space: s,
-		World: w,
-	}
-
-	var velocityable *Velocityable
-	w.AddSystemInterface(CreateVelocitySystem(s), velocityable, nil)
-
-	mainGameScene.GenerateCityBuildings()
-
-	ground := s.FilterByTags(entity.TagGround)
-	for i := 0; i < ground.Length()-1; i++ {
-		left := ground.Get(i).(*resolv.Rectangle)
-		right := ground.Get(i + 1).(*resolv.Rectangle)
-		dist := gomath.Abs((left.X + left.W) - (right.X + right.W))
-		assert.Less(t, dist, float64(150), "distance between buildings must be jumpable")
-	}
-}
-
-func TestBuildingsRemoveGameRuleSystem(t *testing.T) {
-	t.Parallel()
-
-	w := &ecs.World{}
-	s := resolv.NewSpace()
-	mainGameScene := &MainGameScene{
-		Rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
-		Space:          s,
-		World:          w,
-		ScrollingSpeed: math.Vector2{X: -1, Y: 0},
-		Level: &Level{
-			// Disable building spawn Yes we want this
-			StartX: 50000,
-		},
-	}
-
-	gameRuleSystem := CreateGameRuleSystem(mainGameScene)
-	var gameRuleable *GameRuleable
-	w.AddSystemInterface(gameRuleSystem, gameRuleable, nil)
-
-	// Buildings need to move
-	var resolveable *Resolvable
-	w.AddSystemInterface(CreateResolvSystem(s, mainGameScene.InputEnt), resolveable, nil)
-	var velocityable *Velocityable
-	w.AddSystemInterface(CreateVelocitySystem(s), velocityable, nil)
-
-	block := &LevelBlock{
-		BasicEntity: ecs.NewBasic(),
-		TransformComponent: &components.TransformComponent{
-			Postion: math.Vector2{
-				X: 10,
-			},
-		},
-		VelocityComponent:  &components.VelocityComponent{},
-		TileImageComponent: &components.TileImageComponent{},
-		CollisionComponent: &components.CollisionComponent{},
-		ScrollableComponent: &components.ScrollableComponent{
-			Modifier: 1,
-		},
-		IdentityComponent: &components.IdentityComponent{},
-	}
-
-	w.AddEntity(block)
-
-	scrollTestCases := []struct {
-		loops int
-	}{
-		{
-			loops: 12,
-		},
-	}
-
-	for _, testCase := range scrollTestCases {
-		for i := 0; i < testCase.loops-1; i++ {
-			w.Update(1)
-			_, ok := gameRuleSystem.ents[block.ID()]
-			assert.Truef(t, ok, "Should still have building on loop %d", i)
-		}
-		w.Update(1)
-		_, ok := gameRuleSystem.ents[block.ID()]
-		assert.False(t, ok, "building should be removed")
-	}
-
-	w.RemoveEntity(block.BasicEntity)
-}
-
-func TestPlayerSystem(t *testing.T) {
-	t.Parallel()
-
-	w := &ecs.World{}
-	s := resolv.NewSpace()
-	mainGameScene := &MainGameScene{
-		Rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
-		Space:          s,
-		World:          w,
-		ScrollingSpeed: math.Vector2{X: -1, Y: 0},
-		Gravity:        10,
-		State:          gameStateStarting,
-		Level: &Level{
-			Width: 500,
-		},
-	}
-
-	playerSystem := CreatePlayerSystem(mainGameScene)
-	var playerable *Playerable
-	w.AddSystemInterface(playerSystem, playerable, nil)
-	var resolveable *Resolvable
-	w.AddSystemInterface(CreateResolvSystem(s, mainGameScene.InputEnt), resolveable, nil)
-	var velocityable *Velocityable
-	w.AddSystemInterface(CreateVelocitySystem(s), velocityable, nil)
-
-	mainGameScene.GenerateCityBuildings()
-
-	player := entity.CreatePlayer()
-	player.Postion.X = 5
-	w.AddEntity(player)
-
-	player.MovementComponent.PressedDuration[components.InputKindMoveRight] = 1
-	lastPostion := player.Postion
-	w.Update(0.1)
-	assert.Less(t, lastPostion.X, player.Postion.X, "player should move right")
-	player.MovementComponent.PressedDuration[components.InputKindMoveRight] = 0
-
-	player.MovementComponent.PressedDuration[components.InputKindMoveLeft] = 1
-	lastPostion = player.Postion
-	w.Update(0.1)
-	assert.Greater(t, lastPostion.X, player.Postion.X, "player should move left")
-	player.MovementComponent.PressedDuration[components.InputKindMoveLeft] = 0
-
-	w.Update(1)
-
-	// Loop until hit ground
-	i := 0
-	for !player.Collisions.CollidingWith(entity.TagGround) {
-		w.Update(0.1)
-		i++
-		if i > 10000 {
-			t.Error("player fell past ground")
-			t.FailNow()
-		}
-	}
-	// Change state
-	w.Update(0.1)
-
-	// Jump
-	player.MovementComponent.PressedDuration[components.InputKindJump] = 1
-	w.Update(0.1)
-	player.MovementComponent.PressedDuration[components.InputKindJump] = 0
-	player.Cycles = 1
-	w.Update(0.1)
-	assert.Equal(t, player.Cycles, 0, "cycles should get changed on anime change")
-	lastPostion = player.Postion
-	w.Update(0.1)
-	assert.Less(t, lastPostion.Y, player.Postion.Y)
-
-	// Player jumping
-	for !player.Collisions.CollidingWith(entity.TagGround) {
-		w.Update(0.1)
-	}
-
-	player.MovementComponent.PressedDuration[components.InputKindMoveRight] = 1
-	lastPostion = player.Postion
-	w.Update(0.9)
-	assert.Less(t, lastPostion.X, player.Postion.X, "player should move right")
-
-	player.MovementComponent.PressedDuration[components.InputKindMoveLeft] = 1
-	lastPostion = player.Postion
-	w.Update(0.01)
-	assert.Greater(t, lastPostion.X, player.Postion.X, "player should move left")
-
-	// Check bullet is flipped
-	player.MovementComponent.PressedDuration[components.InputKindShoot] = 1
-	// This is very sensitive since a bullet can be destroyed if the player is too far left
-	w.Update(0.001)
-	bullet, ok := s.FilterByTags(entity.TagBullet).Get(0).GetData().(*entity.Bullet)
-	assert.True(t, ok, "should be bullet attached to data")
-	assert.True(t, bullet.Options.InvertX, "bullet should be flipped")
-	assert.Less(t, bullet.Speed.X, float64(0), "bullet should be moving left")
-
-	w.RemoveEntity(player.BasicEntity)
-}
-
-func TestDestoryOnAnimeableGameRuleSystem(t *testing.T) {
-	t.Parallel()
-
-	w := &ecs.World{}
-	s := resolv.NewSpace()
-	mainGameScene := &MainGameScene{
-		Rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
-		Space:          s,
-		World:          w,
-		ScrollingSpeed: math.Vector2{X: -1, Y: 0},
-		Gravity:        10,
-		Level: &Level{
-			// Disable building spawn
-			StartX: 50000,
-			Width:  500,
-		},
-	}
-
-	gameRuleSystem := CreateGameRuleSystem(mainGameScene)
-	var gameRuleable *GameRuleable
-	w.AddSystemInterface(gameRuleSystem, gameRuleable, nil)
-	var animeable *Animeable
-	w.AddSystemInterface(CreateAnimeSystem(), animeable, nil)
-
-	ent := &struct {
-		ecs.BasicEntity
-		*components.TransformComponent
-		*components.AnimeComponent
-		*components.DestoryOnAnimeComponent
-		*components.TileImageComponent
-	}{
-		BasicEntity: ecs.NewBasic(),
-		TransformComponent: &components.TransformComponent{
-			Size: math.Vector2{X: 1, Y: 1},
-		},
-		AnimeComponent: &components.AnimeComponent{
-			FrameDuration:  1 * time.Second,
-			FrameRemaining: 1 * time.Second,
-		},
-		DestoryOnAnimeComponent: &components.DestoryOnAnimeComponent{
-			CyclesTilDeath: 1,
-		},
-		TileImageComponent: &components.TileImageComponent{
-			Active:  true,
-			TileMap: components.CreateTileMap(1, 1, ebiten.NewImage(1, 1), 1),
-		},
-	}
-	w.AddEntity(ent)
-	_, ok := gameRuleSystem.ents[ent.ID()]
-	assert.True(t, ok, "entity should be removed once anime completed")
-
-	for ent.Cycles <= 0 {
-		w.Update(1)
-	}
-	w.Update(1)
-
-	_, ok = gameRuleSystem.ents[ent.ID()]
-	assert.False(t, ok, "entity should be removed once anime completed")
-}
-
-func TestEnemyBiscuit(t *testing.T) {
-	t.Parallel()
-
-	w := &ecs.World{}
-	s := resolv.NewSpace()
-	mainGameScene := &MainGameScene{
-		Rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
-		Space:   s,
-		World:   w,
-		Gravity: 10,
-		Level: &Level{
-			// Disable building spawn
-			StartX: 50000,
-			Width:  500,
-		},
-	}
-
-	gameRuleSystem := CreateGameRuleSystem(mainGameScene)
-	var gameRuleable *GameRuleable
-	w.AddSystemInterface(gameRuleSystem, gameRuleable, nil)
-	var animeable *Animeable
-	w.AddSystemInterface(CreateAnimeSystem(), animeable, nil)
-	lifeSystem := CreateLifeSystem()
-	var lifeable *Lifeable
-	w.AddSystemInterface(lifeSystem, lifeable, nil)
-
-	enemy := entity.CreateBiscuitEnemy()
-	w.AddEntity(enemy)
-
-	w.Update(0.1)
-	_, ok := gameRuleSystem.ents[enemy.ID()]
-	assert.True(t, ok, "ufo should exist still")
-
-	enemy.DamageEvents = append(enemy.DamageEvents, &components.DamageEvent{
-		Damage: enemy.HP + 1,
-	})
-	w.Update(0.1)
-	_, ok = gameRuleSystem.ents[enemy.ID()]
-	assert.False(t, ok, "ufo should no longer exist")
-
-	assert.Greater(t, len(lifeSystem.activePlayerPool), 0, "death sound should be triggered")
-}
-
-func TestUfoBiscuit(t *testing.T) {
-	t.Parallel()
-
-	w := &ecs.World{}
-	s := resolv.NewSpace()
-	mainGameScene := &MainGameScene{
-		Rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
-		Space:   s,
-		World:   w,
-		Gravity: 10,
-		Level: &Level{
-			// Disable building spawn
-			StartX: 50000,
-			Width:  500,
-		},
-	}
-
-	gameRuleSystem := CreateGameRuleSystem(mainGameScene)
-	var gameRuleable *GameRuleable
-	w.AddSystemInterface(gameRuleSystem, gameRuleable, nil)
-	var animeable *Animeable
-	w.AddSystemInterface(CreateAnimeSystem(), animeable, nil)
-	lifeSystem := CreateLifeSystem()
-	var lifeable *Lifeable
-	w.AddSystemInterface(lifeSystem, lifeable, nil)
-
-	ufo := entity.CreateUfoBiscuitEnemy()
-	w.AddEntity(ufo)
-
-	w.Update(0.1)
-	_, ok := gameRuleSystem.ents[ufo.ID()]
-	assert.True(t, ok, "ufo should exist still")
-
-	ufo.DamageEvents = append(ufo.DamageEvents, &components.DamageEvent{
-		Damage: ufo.HP + 1,
-	})
-	w.Update(0.1)
-	_, ok = gameRuleSystem.ents[ufo.ID()]
-	assert.False(t, ok, "ufo should no longer exist")
-
-	assert.Greater(t, len(lifeSystem.activePlayerPool), 0, "death sound should be triggered")
-}
-
-func TestCompleteMainGameScene(t *testing.T) {
-
-	g := &Game{
-		audioCtx: audio.CurrentContext(),
-	}
-	mgs := &MainGameScene{}
-
-	assert.NotPanics(t, func() {
-		mgs.Start(g)
-	})
-
-	assert.NotNil(t, mgs.Rand)
-	assert.NotNil(t, mgs.World)
-	assert.NotNil(t, mgs.Space)
-	assert.NotNil(t, mgs.Level)
-
-	assert.NotPanics(t, func() {
-		mgs.Update(100*time.Millisecond, g)
-	})
-	assert.Less(t, mgs.TimeElapsed, 150*time.Millisecond)
-
-	mgs.InputEnt.PressedDuration[components.InputKindFastGameSpeed] = 1
-	mgs.Update(100*time.Millisecond, g)
-	assert.Greater(t, mgs.TimeElapsed, 400*time.Millisecond)
-
-	screen := ebiten.NewImage(windowWidth, windowHeight)
-	screen.Fill(color.White)
-	mgs.Draw(screen)
-	assert.False(t, emptyImage(screen))
-
-	mgs.End(g)
-
-	assert.Nil(t, mgs.Rand)
-	assert.Nil(t, mgs.World)
-	assert.Nil(t, mgs.Space)
-	assert.Nil(t, mgs.Level)
-}
-
-func TestKaraokeScene(t *testing.T) {
-
-	g := &Game{
-		audioCtx: audio.CurrentContext(),
-	}
-	ks := &KaraokeScene{}
-
-	ks.Session = &KaraokeSession{
-		Inputs: []*KaraokeInput{
-			{},
-		},
-		Backgrounds: []*KaraokeBackground{
-			{
-				Duration: 0,
-				Image:    base64.StdEncoding.EncodeToString([]byte(assets.ImageSkyCity.Data)),
-			},
-		},
-		Music:      &assets.MusicPdRockBackground.Data,
-		SampleRate: assets.MusicPdRockBackground.SampleRate,
-	}
-
-	assert.NotPanics(t, func() {
-		ks.Start(g)
-	})
-
-	assert.NotNil(t, ks.Session)
-
-	assert.NotPanics(t, func() {
-		ks.Update(100*time.Millisecond, g)
-	})
-
-	screen := ebiten.NewImage(windowWidth, windowHeight)
-	screen.Fill(color.White)
-	ks.Draw(screen)
-	assert.False(t, emptyImage(screen))
-
-	ks.End(g)
-
-	assert.Nil(t, ks.Session)
-}
-
-func TestTitleScene(t *testing.T) {
-
-	g := &Game{
-		audioCtx: audio.CurrentContext(),
-	}
-	ts := &TitleScene{}
-
-	assert.NotPanics(t, func() {
-		ts.Start(g)
-	})
-
-	assert.NotPanics(t, func() {
-		ts.Update(100*time.Millisecond, g)
-	})
-
-	screen := ebiten.NewImage(windowWidth, windowHeight)
-	screen.Fill(color.White)
-	ts.Draw(screen)
-	assert.False(t, emptyImage(screen))
-
-	assert.NotPanics(t, func() {
-		ts.End(g)
-	})
-}
-
-func TestCompleteGame(t *testing.T) {
-
-	g := &Game{
-		audioCtx: audio.CurrentContext(),
-	}
-
-	g.Update()
-
-	screen := ebiten.NewImage(windowWidth, windowHeight)
-	screen.Fill(color.White)
-	g.Draw(screen)
-	assert.False(t, emptyImage(screen))
-
-	g.Layout(100, 100)
-}
+package game
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	gomath "math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/SolarLune/resolv"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/sardap/walk-good-maybe-hd/assets"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+	"github.com/sardap/walk-good-maybe-hd/math"
+	"github.com/sardap/walk-good-maybe-hd/replay"
+	"github.com/sardap/walk-good-maybe-hd/scores"
+	"github.com/sardap/walk-good-maybe-hd/settings"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+	"github.com/stretchr/testify/assert"
+)
+
+func emptyImage(img *ebiten.Image) bool {
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			if img.At(x, y) != color.White {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+type fakeRand struct {
+	seq []int64
+	top int
+}
+
+func (f *fakeRand) Int63() int64 {
+	if f.top >= len(f.seq) {
+		return rand.Int63()
+	}
+	result := f.seq[f.top]
+	f.top++
+	return result
+}
+
+func (*fakeRand) Seed(seed int64) {
+}
+
+func findSeed(min, max float64) (seed int64, num float64) {
+	for {
+		seed = rand.Int63()
+		num = rand.New(&fakeRand{seq: []int64{seed}}).Float64()
+		if num >= min && num <= max {
+			return
+		}
+	}
+}
+
+func TestCreateAllBuildings(t *testing.T) {
+	t.Parallel()
+
+	s := resolv.NewSpace()
+	mainGameScene := &MainGameScene{
+		Level: &Level{},
+		Space: s,
+	}
+
+	// Building 5 is non standard shape
+	testCases := []struct {
+		name string
+		seq  []int64
+	}{
+		{name: "building 0"},
+		{name: "building 1"},
+		{name: "building 2"},
+		{name: "building 3"},
+		{name: "building 4"},
+		{name: "building 5"},
+	}
+
+	for i, testcase := range testCases {
+		step := 1.0 / float64(len(testCases))
+		max := step * float64(i+1)
+		seed, _ := findSeed(max-step, max)
+
+		mainGameScene.Rand = rand.New(&fakeRand{seq: []int64{seed}})
+
+		LevelBlock := createRandomLevelBlock(mainGameScene.Rand, ecs.NewBasic())
+		expected := LevelBlock.TileMap.TileWidth * LevelBlock.TileMap.TileXNum
+		assert.Equalf(t, expected, int(LevelBlock.Size.X), "invalid tileWidth for %s", testcase.name)
+
+		for _, tile := range LevelBlock.TileMap.Map {
+			assert.GreaterOrEqualf(t, tile, int16(0), "invalid greater or equal %s", testcase.name)
+		}
+	}
+}
+
+func TestCityLevelGenerate(t *testing.T) {
+	t.Parallel()
+
+	w := &ecs.World{}
+	s := resolv.NewSpace()
+	mainGameScene := &MainGameScene{
+		Level: &Level{},
+		Rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		Space: s,
+		World: w,
+	}
+
+	var velocityable *Velocityable
+	AddSystemInterface(w, CreateVelocitySystem(s), velocityable, nil)
+
+	mainGameScene.GenerateCityBuildings()
+
+	ground := s.FilterByTags(entity.TagGround)
+	for i := 0; i < ground.Length()-1; i++ {
+		left := ground.Get(i).(*resolv.Rectangle)
+		right := ground.Get(i + 1).(*resolv.Rectangle)
+		dist := gomath.Abs((left.X + left.W) - (right.X + right.W))
+		assert.Less(t, dist, float64(150), "distance between buildings must be jumpable")
+	}
+}
+
+func TestCreateLedges(t *testing.T) {
+	t.Parallel()
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	billboards, balconies := 0, 0
+	for i := 0; i < 200; i++ {
+		building := createRandomLevelBlock(r, ecs.NewBasic())
+		building.Postion = math.Vector2{X: 500, Y: 900}
+		tileWidth := float64(building.TileMap.TileWidth)
+
+		for _, ledge := range createLedges(r, building) {
+			assert.True(t, ledge.OneWay, "ledges should be one way")
+			assert.Equal(t, building.TileMap.TilesImg, ledge.TileMap.TilesImg, "ledges should look like their building")
+			assert.Equal(t, tileWidth, ledge.Size.Y, "ledges should be a tile high")
+			assert.Empty(t, ledge.GetSpawnProbabilities(), "nothing should spawn on ledges")
+			for _, tile := range ledge.TileMap.Map {
+				assert.GreaterOrEqual(t, tile, int16(0))
+			}
+
+			if ledge.Postion.Y < building.Postion.Y {
+				billboards++
+				assert.GreaterOrEqual(t, ledge.Postion.X, building.Postion.X, "billboards should be over the roof")
+				assert.LessOrEqual(t, ledge.Postion.X+ledge.Size.X, building.Postion.X+building.Size.X)
+				assert.LessOrEqual(t, building.Postion.Y-ledge.Postion.Y, tileWidth*maxBillboardHeight)
+			} else {
+				balconies++
+				assert.Equal(t, building.Postion.X+building.Size.X, ledge.Postion.X, "balconies should stick out the right")
+				assert.LessOrEqual(t, ledge.Postion.Y+ledge.Size.Y, building.Postion.Y+building.Size.Y, "balconies should be on the building")
+				assert.LessOrEqual(t, ledge.Postion.Y-building.Postion.Y, tileWidth*maxBalconyDepth)
+			}
+		}
+	}
+
+	assert.NotZero(t, billboards)
+	assert.NotZero(t, balconies)
+}
+
+func TestBuildingsRemoveGameRuleSystem(t *testing.T) {
+	t.Parallel()
+
+	w := &ecs.World{}
+	s := resolv.NewSpace()
+	mainGameScene := &MainGameScene{
+		Rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		Space:          s,
+		World:          w,
+		ScrollingSpeed: math.Vector2{X: -1, Y: 0},
+		Level: &Level{
+			// Disable building spawn Yes we want this
+			StartX: 50000,
+		},
+	}
+
+	gameRuleSystem := CreateGameRuleSystem(mainGameScene)
+	var gameRuleable *GameRuleable
+	AddSystemInterface(w, gameRuleSystem, gameRuleable, nil)
+
+	// Buildings need to move
+	var resolveable *Resolvable
+	AddSystemInterface(w, CreateResolvSystem(s, mainGameScene.InputEnt), resolveable, nil)
+	var velocityable *Velocityable
+	AddSystemInterface(w, CreateVelocitySystem(s), velocityable, nil)
+
+	block := &LevelBlock{
+		BasicEntity: ecs.NewBasic(),
+		TransformComponent: &components.TransformComponent{
+			Postion: math.Vector2{
+				X: 10,
+			},
+		},
+		VelocityComponent:  &components.VelocityComponent{},
+		TileImageComponent: &components.TileImageComponent{},
+		CollisionComponent: &components.CollisionComponent{},
+		ScrollableComponent: &components.ScrollableComponent{
+			Modifier: 1,
+		},
+		IdentityComponent: &components.IdentityComponent{},
+	}
+
+	w.AddEntity(block)
+
+	scrollTestCases := []struct {
+		loops int
+	}{
+		{
+			loops: 12,
+		},
+	}
+
+	for _, testCase := range scrollTestCases {
+		for i := 0; i < testCase.loops-1; i++ {
+			w.Update(1)
+			_, ok := gameRuleSystem.ents[block.ID()]
+			assert.Truef(t, ok, "Should still have building on loop %d", i)
+		}
+		w.Update(1)
+		_, ok := gameRuleSystem.ents[block.ID()]
+		assert.False(t, ok, "building should be removed")
+	}
+
+	w.RemoveEntity(block.BasicEntity)
+}
+
+func TestPlayerSystem(t *testing.T) {
+	t.Parallel()
+
+	w := &ecs.World{}
+	s := resolv.NewSpace()
+	mainGameScene := &MainGameScene{
+		Rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		Space:          s,
+		World:          w,
+		ScrollingSpeed: math.Vector2{X: -1, Y: 0},
+		Gravity:        10,
+		State:          gameStateStarting,
+		Level: &Level{
+			Width: 500,
+		},
+	}
+
+	playerSystem := CreatePlayerSystem(mainGameScene)
+	var playerable *Playerable
+	AddSystemInterface(w, playerSystem, playerable, nil)
+	resolvSystem := CreateResolvSystem(s, mainGameScene.InputEnt)
+	var resolveable *Resolvable
+	AddSystemInterface(w, resolvSystem, resolveable, nil)
+	var velocityable *Velocityable
+	AddSystemInterface(w, CreateVelocitySystem(s), velocityable, nil)
+
+	mainGameScene.GenerateCityBuildings()
+
+	player := entity.CreatePlayer()
+	player.Postion.X = 5
+	// Buildings hang above 0 with no level height, start with the top of the
+	// hitbox touching them
+	player.Postion.Y = -player.Hitbox.Rects[0].Y
+	w.AddEntity(player)
+
+	player.MovementComponent.PressedDuration[components.InputKindMoveRight] = 1
+	lastPostion := player.Postion
+	w.Update(0.1)
+	assert.Less(t, lastPostion.X, player.Postion.X, "player should move right")
+	player.MovementComponent.PressedDuration[components.InputKindMoveRight] = 0
+
+	player.MovementComponent.PressedDuration[components.InputKindMoveLeft] = 1
+	lastPostion = player.Postion
+	w.Update(0.1)
+	assert.Greater(t, lastPostion.X, player.Postion.X, "player should move left")
+	player.MovementComponent.PressedDuration[components.InputKindMoveLeft] = 0
+
+	w.Update(1)
+
+	// Loop until hit ground
+	i := 0
+	for !player.Collisions.CollidingWith(entity.TagGround) {
+		w.Update(0.1)
+		i++
+		if i > 10000 {
+			t.Error("player fell past ground")
+			t.FailNow()
+		}
+	}
+	// Change state
+	w.Update(0.1)
+	assert.Equal(t, entity.DefaultHitboxes().For("whaleIdle"), player.Hitbox, "hitbox should follow the image")
+	// Last walked left so the legs are on the other side
+	assert.True(t, player.TileMap.Options.InvertX)
+	layoutHitbox(player)
+	legs := hitboxRects(player)[1]
+	assert.Equal(t, player.Postion.X+player.Size.X-legs.X-legs.W, parts(player)[1].X, "hitbox should be mirrored facing left")
+
+	// Jump
+	player.MovementComponent.PressedDuration[components.InputKindJump] = 1
+	w.Update(0.1)
+	assert.Equal(t, entity.DefaultHitboxes().For("whaleJump"), player.Hitbox, "hitbox should follow the image")
+	player.MovementComponent.PressedDuration[components.InputKindJump] = 0
+	player.Cycles = 1
+	w.Update(0.1)
+	assert.Equal(t, player.Cycles, 0, "cycles should get changed on anime change")
+	assert.Equal(t, entity.DefaultHitboxes().For("whaleAir"), player.Hitbox, "hitbox should follow the image")
+	lastPostion = player.Postion
+	w.Update(0.1)
+	assert.Less(t, lastPostion.Y, player.Postion.Y)
+
+	// Player jumping
+	for !player.Collisions.CollidingWith(entity.TagGround) {
+		w.Update(0.1)
+	}
+
+	player.MovementComponent.PressedDuration[components.InputKindMoveRight] = 1
+	lastPostion = player.Postion
+	w.Update(0.9)
+	assert.Less(t, lastPostion.X, player.Postion.X, "player should move right")
+
+	player.MovementComponent.PressedDuration[components.InputKindMoveLeft] = 1
+	lastPostion = player.Postion
+	w.Update(0.01)
+	assert.Greater(t, lastPostion.X, player.Postion.X, "player should move left")
+
+	// Check bullet is flipped
+	player.MovementComponent.PressedDuration[components.InputKindShoot] = 1
+	// This is very sensitive since a bullet can be destroyed if the player is too far left
+	w.Update(0.001)
+	owner, ok := resolvSystem.Owner(s.FilterByTags(entity.TagBullet).Get(0))
+	assert.True(t, ok, "bullet shape should have an owner")
+	bullet, ok := owner.(*entity.Bullet)
+	assert.True(t, ok, "owner should be a bullet")
+	assert.True(t, bullet.Options.InvertX, "bullet should be flipped")
+	assert.Less(t, bullet.Speed.X, float64(0), "bullet should be moving left")
+
+	w.RemoveEntity(player.BasicEntity)
+
+	// The rest is done well above the city so the only ground is faked
+	onGround := components.CollisionEvents{{Tags: []int{entity.TagGround}, Normal: math.Vector2{Y: -1}}}
+	createPlayer := func(state components.MainGamePlayerState) *entity.Player {
+		result := entity.CreatePlayer()
+		result.Postion.Y = -100000
+		result.JumpPower = startingPlayerJumpPower
+		result.State = state
+		w.AddEntity(result)
+		return result
+	}
+
+	// Coyote time
+	player = createPlayer(components.MainGamePlayerStateGroundIdling)
+	player.Collisions = onGround
+	w.Update(0.01)
+	w.Update(0.05)
+	assert.Equal(t, components.MainGamePlayerStateGroundIdling, player.State, "should still be able to jump just after leaving the ground")
+	player.MovementComponent.PressedDuration[components.InputKindJump] = 1
+	w.Update(0.01)
+	assert.Equal(t, components.MainGamePlayerStatePrepareJumping, player.State, "jump should work in coyote time")
+	w.RemoveEntity(player.BasicEntity)
+
+	player = createPlayer(components.MainGamePlayerStateGroundIdling)
+	player.Collisions = onGround
+	w.Update(0.01)
+	w.Update(0.2)
+	assert.Equal(t, components.MainGamePlayerStateFlying, player.State, "should fall once coyote time is up")
+	player.MovementComponent.PressedDuration[components.InputKindJump] = 1
+	w.Update(0.01)
+	assert.Equal(t, components.MainGamePlayerStateFlying, player.State, "can't jump after coyote time")
+	w.RemoveEntity(player.BasicEntity)
+
+	// Jump buffer
+	player = createPlayer(components.MainGamePlayerStateFlying)
+	player.MovementComponent.JustPressed[components.InputKindJump] = true
+	w.Update(0.01)
+	player.MovementComponent.JustPressed[components.InputKindJump] = false
+	w.Update(0.05)
+	player.Collisions = onGround
+	w.Update(0.01)
+	assert.Equal(t, components.MainGamePlayerStatePrepareJumping, player.State, "jump pressed just before landing should happen")
+	w.RemoveEntity(player.BasicEntity)
+
+	player = createPlayer(components.MainGamePlayerStateFlying)
+	player.MovementComponent.JustPressed[components.InputKindJump] = true
+	w.Update(0.01)
+	player.MovementComponent.JustPressed[components.InputKindJump] = false
+	w.Update(0.5)
+	player.Collisions = onGround
+	w.Update(0.01)
+	assert.Equal(t, components.MainGamePlayerStateGroundIdling, player.State, "jump pressed too early should be forgotten")
+	w.RemoveEntity(player.BasicEntity)
+
+	// Variable jump height
+	player = createPlayer(components.MainGamePlayerStatePrepareJumping)
+	player.MovementComponent.PressedDuration[components.InputKindJump] = 1
+	player.Cycles = 1
+	w.Update(0.01)
+	assert.Equal(t, components.MainGamePlayerStateJumping, player.State)
+	w.Update(0.1)
+	assert.Greater(t, player.JumpPowerRemaning, startingPlayerJumpPower*releasedJumpPower, "holding jump keeps going up")
+	player.MovementComponent.PressedDuration[components.InputKindJump] = 0
+	w.Update(0.01)
+	assert.LessOrEqual(t, player.JumpPowerRemaning, startingPlayerJumpPower*releasedJumpPower, "letting go should cut the jump short")
+	w.RemoveEntity(player.BasicEntity)
+
+	player = createPlayer(components.MainGamePlayerStateBouncing)
+	player.JumpPowerRemaning = startingPlayerJumpPower
+	w.Update(0.01)
+	assert.Greater(t, player.JumpPowerRemaning, startingPlayerJumpPower*releasedJumpPower, "bounces shouldn't be cut short")
+	w.RemoveEntity(player.BasicEntity)
+}
+
+func TestDestoryOnAnimeableGameRuleSystem(t *testing.T) {
+	t.Parallel()
+
+	w := &ecs.World{}
+	s := resolv.NewSpace()
+	mainGameScene := &MainGameScene{
+		Rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		Space:          s,
+		World:          w,
+		ScrollingSpeed: math.Vector2{X: -1, Y: 0},
+		Gravity:        10,
+		Level: &Level{
+			// Disable building spawn
+			StartX: 50000,
+			Width:  500,
+		},
+	}
+
+	gameRuleSystem := CreateGameRuleSystem(mainGameScene)
+	var gameRuleable *GameRuleable
+	AddSystemInterface(w, gameRuleSystem, gameRuleable, nil)
+	var animeable *Animeable
+	AddSystemInterface(w, CreateAnimeSystem(), animeable, nil)
+
+	ent := &struct {
+		ecs.BasicEntity
+		*components.TransformComponent
+		*components.AnimeComponent
+		*components.DestoryOnAnimeComponent
+		*components.TileImageComponent
+	}{
+		BasicEntity: ecs.NewBasic(),
+		TransformComponent: &components.TransformComponent{
+			Size: math.Vector2{X: 1, Y: 1},
+		},
+		AnimeComponent: &components.AnimeComponent{
+			FrameDuration:  1 * time.Second,
+			FrameRemaining: 1 * time.Second,
+		},
+		DestoryOnAnimeComponent: &components.DestoryOnAnimeComponent{
+			CyclesTilDeath: 1,
+		},
+		TileImageComponent: &components.TileImageComponent{
+			Active:  true,
+			TileMap: components.CreateTileMap(1, 1, ebiten.NewImage(1, 1), 1),
+		},
+	}
+	w.AddEntity(ent)
+	_, ok := gameRuleSystem.ents[ent.ID()]
+	assert.True(t, ok, "entity should be removed once anime completed")
+
+	for ent.Cycles <= 0 {
+		w.Update(1)
+	}
+	w.Update(1)
+
+	_, ok = gameRuleSystem.ents[ent.ID()]
+	assert.False(t, ok, "entity should be removed once anime completed")
+}
+
+func TestEnemyBiscuit(t *testing.T) {
+	t.Parallel()
+
+	w := &ecs.World{}
+	s := resolv.NewSpace()
+	mainGameScene := &MainGameScene{
+		Rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		Space:   s,
+		World:   w,
+		Gravity: 10,
+		Level: &Level{
+			// Disable building spawn
+			StartX: 50000,
+			Width:  500,
+		},
+	}
+
+	AddSystem(w, CreateEventSystem(CreateEventBus()))
+	gameRuleSystem := CreateGameRuleSystem(mainGameScene)
+	var gameRuleable *GameRuleable
+	AddSystemInterface(w, gameRuleSystem, gameRuleable, nil)
+	var animeable *Animeable
+	AddSystemInterface(w, CreateAnimeSystem(), animeable, nil)
+	var lifeable *Lifeable
+	AddSystemInterface(w, CreateLifeSystem(), lifeable, nil)
+	effectSystem := CreateEffectSystem(mainGameScene)
+	AddSystem(w, effectSystem)
+
+	enemy := entity.CreateBiscuitEnemy()
+	w.AddEntity(enemy)
+
+	w.Update(0.1)
+	_, ok := gameRuleSystem.ents[enemy.ID()]
+	assert.True(t, ok, "ufo should exist still")
+
+	enemy.DamageEvents = append(enemy.DamageEvents, &components.DamageEvent{
+		Damage: enemy.HP + 1,
+	})
+	w.Update(0.1)
+	_, ok = gameRuleSystem.ents[enemy.ID()]
+	assert.False(t, ok, "ufo should no longer exist")
+
+	assert.Greater(t, len(effectSystem.activePlayerPool), 0, "death sound should be triggered")
+
+	var corpses []*entity.Corpse
+	for _, ent := range gameRuleSystem.ents {
+		if corpse, ok := ent.(*entity.Corpse); ok {
+			corpses = append(corpses, corpse)
+		}
+	}
+	if assert.Len(t, corpses, 1, "should leave a corpse behind") {
+		assert.Equal(t, entity.LayerCorpse, corpses[0].CollisionLayer)
+		assert.False(t, entity.CollisionRuleFor(entity.LayerPlayer).Blocks.Has(corpses[0].CollisionLayer), "the player should pass through it")
+	}
+}
+
+func TestUfoBiscuit(t *testing.T) {
+	t.Parallel()
+
+	w := &ecs.World{}
+	s := resolv.NewSpace()
+	mainGameScene := &MainGameScene{
+		Rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		Space:   s,
+		World:   w,
+		Gravity: 10,
+		Level: &Level{
+			// Disable building spawn
+			StartX: 50000,
+			Width:  500,
+		},
+	}
+
+	AddSystem(w, CreateEventSystem(CreateEventBus()))
+	gameRuleSystem := CreateGameRuleSystem(mainGameScene)
+	var gameRuleable *GameRuleable
+	AddSystemInterface(w, gameRuleSystem, gameRuleable, nil)
+	var animeable *Animeable
+	AddSystemInterface(w, CreateAnimeSystem(), animeable, nil)
+	var lifeable *Lifeable
+	AddSystemInterface(w, CreateLifeSystem(), lifeable, nil)
+	effectSystem := CreateEffectSystem(mainGameScene)
+	AddSystem(w, effectSystem)
+
+	ufo := entity.CreateUfoBiscuitEnemy()
+	w.AddEntity(ufo)
+
+	w.Update(0.1)
+	_, ok := gameRuleSystem.ents[ufo.ID()]
+	assert.True(t, ok, "ufo should exist still")
+
+	ufo.DamageEvents = append(ufo.DamageEvents, &components.DamageEvent{
+		Damage: ufo.HP + 1,
+	})
+	w.Update(0.1)
+	_, ok = gameRuleSystem.ents[ufo.ID()]
+	assert.False(t, ok, "ufo should no longer exist")
+
+	assert.Greater(t, len(effectSystem.activePlayerPool), 0, "death sound should be triggered")
+}
+
+func TestCompleteMainGameScene(t *testing.T) {
+
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+	mgs := &MainGameScene{}
+
+	assert.NotPanics(t, func() {
+		mgs.Start(g)
+	})
+
+	assert.NotNil(t, mgs.Rand)
+	assert.NotNil(t, mgs.World)
+	assert.NotNil(t, mgs.Space)
+	assert.NotNil(t, mgs.Level)
+
+	assert.NotPanics(t, func() {
+		mgs.Update(100*time.Millisecond, g)
+	})
+	assert.Less(t, mgs.TimeElapsed, 150*time.Millisecond)
+
+	mgs.InputEnt.PressedDuration[components.InputKindFastGameSpeed] = 1
+	mgs.Update(100*time.Millisecond, g)
+	assert.Greater(t, mgs.TimeElapsed, 400*time.Millisecond)
+
+	screen := ebiten.NewImage(windowWidth, windowHeight)
+	screen.Fill(color.White)
+	mgs.Draw(screen)
+	assert.False(t, emptyImage(screen))
+
+	mgs.End(g)
+
+	assert.Nil(t, mgs.Rand)
+	assert.Nil(t, mgs.World)
+	assert.Nil(t, mgs.Space)
+	assert.Nil(t, mgs.Level)
+}
+
+func TestKaraokeScene(t *testing.T) {
+
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+	ks := &KaraokeScene{}
+
+	ks.Session = &KaraokeSession{
+		Inputs: []*KaraokeInput{
+			{},
+		},
+		Backgrounds: []*KaraokeBackground{
+			{
+				Duration: 0,
+				Image:    base64.StdEncoding.EncodeToString([]byte(assets.ImageSkyCity.Data)),
+			},
+		},
+		Music:      &assets.MusicPdRockBackground.Data,
+		SampleRate: assets.MusicPdRockBackground.SampleRate,
+	}
+
+	assert.NotPanics(t, func() {
+		ks.Start(g)
+	})
+
+	assert.NotNil(t, ks.Session)
+
+	assert.NotPanics(t, func() {
+		ks.Update(100*time.Millisecond, g)
+	})
+
+	screen := ebiten.NewImage(windowWidth, windowHeight)
+	screen.Fill(color.White)
+	ks.Draw(screen)
+	assert.False(t, emptyImage(screen))
+
+	ks.End(g)
+
+	assert.Nil(t, ks.Session)
+}
+
+func TestTitleScene(t *testing.T) {
+
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+	ts := &TitleScene{}
+
+	assert.NotPanics(t, func() {
+		ts.Start(g)
+	})
+
+	assert.NotPanics(t, func() {
+		ts.Update(100*time.Millisecond, g)
+	})
+
+	screen := ebiten.NewImage(windowWidth, windowHeight)
+	screen.Fill(color.White)
+	ts.Draw(screen)
+	assert.False(t, emptyImage(screen))
+
+	assert.NotPanics(t, func() {
+		ts.End(g)
+	})
+}
+
+func TestCompleteGame(t *testing.T) {
+
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+
+	g.Update()
+
+	screen := ebiten.NewImage(windowWidth, windowHeight)
+	screen.Fill(color.White)
+	g.Draw(screen)
+	assert.False(t, emptyImage(screen))
+
+	g.Layout(100, 100)
+}
+
+type countingScene struct {
+	updates     []time.Duration
+	next        Scene
+	started     bool
+	ended       bool
+	covered     bool
+	transparent bool
+	draws       int
+}
+
+func (c *countingScene) Start(*Game) {
+	c.started = true
+}
+
+func (c *countingScene) End(*Game) {
+	c.ended = true
+}
+
+func (c *countingScene) Covered(*Game) {
+	c.covered = true
+}
+
+func (c *countingScene) Uncovered(*Game) {
+	c.covered = false
+}
+
+func (c *countingScene) Transparent() bool {
+	return c.transparent
+}
+
+func (c *countingScene) Update(dt time.Duration, game *Game) {
+	c.updates = append(c.updates, dt)
+	if c.next != nil {
+		game.ChangeScene(c.next)
+	}
+}
+
+func (c *countingScene) Draw(*ebiten.Image) {
+	c.draws++
+}
+
+func TestFixedTimestep(t *testing.T) {
+	t.Parallel()
+
+	g := &Game{}
+	g.SetTickRate(100)
+	scene := &countingScene{}
+	g.ChangeScene(scene)
+
+	assert.Zero(t, g.Advance(5*time.Millisecond), "not enough time for a tick")
+	assert.Equal(t, 1, g.Advance(5*time.Millisecond), "leftover time should carry over")
+	assert.Equal(t, 3, g.Advance(35*time.Millisecond))
+	for _, dt := range scene.updates {
+		assert.Equal(t, 10*time.Millisecond, dt, "every tick should be the same size")
+	}
+
+	// Stalled frame
+	scene.updates = nil
+	assert.Equal(t, maxTicksPerUpdate, g.Advance(10*time.Second), "catch up should be capped")
+	assert.Zero(t, g.Advance(0), "dropped time should not be replayed")
+
+	// Scene change stops stepping the old scene
+	next := &countingScene{}
+	scene.next = next
+	scene.updates = nil
+	assert.Equal(t, 1, g.Advance(30*time.Millisecond))
+	assert.Len(t, scene.updates, 1)
+	assert.Empty(t, next.updates)
+	assert.Equal(t, 1, g.Advance(10*time.Millisecond))
+	assert.Len(t, next.updates, 1)
+}
+
+func TestSeededMainGameScene(t *testing.T) {
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+
+	buildings := func(m *MainGameScene) (result []math.Vector2) {
+		ground := m.Space.FilterByTags(entity.TagGround)
+		for i := 0; i < ground.Length(); i++ {
+			rect := ground.Get(i).(*resolv.Rectangle)
+			result = append(result, math.Vector2{X: rect.W, Y: rect.H})
+		}
+		return
+	}
+
+	first := &MainGameScene{Seed: 1234}
+	first.Start(g)
+	first.Update(100*time.Millisecond, g)
+	expected := buildings(first)
+	first.End(g)
+
+	second := &MainGameScene{Seed: 1234}
+	second.Start(g)
+	second.Update(100*time.Millisecond, g)
+	assert.Equal(t, expected, buildings(second), "same seed should give the same city")
+	second.End(g)
+
+	random := &MainGameScene{}
+	random.Start(g)
+	assert.NotZero(t, random.Seed, "seed should be picked when not given")
+	random.End(g)
+}
+
+// scriptedKeyboard holds down each key for its span of ticks
+type scriptedKeyboard struct {
+	tick  int
+	spans map[ebiten.Key][2]int
+}
+
+func (s *scriptedKeyboard) KeyPressDuration(k ebiten.Key) int {
+	span, ok := s.spans[k]
+	if !ok || s.tick < span[0] || s.tick >= span[1] {
+		return 0
+	}
+
+	return s.tick - span[0] + 1
+}
+
+func (s *scriptedKeyboard) IsKeyJustPressed(k ebiten.Key) bool {
+	span, ok := s.spans[k]
+	return ok && s.tick == span[0]
+}
+
+func (s *scriptedKeyboard) IsKeyJustReleased(k ebiten.Key) bool {
+	span, ok := s.spans[k]
+	return ok && s.tick == span[1]
+}
+
+func TestRecordReplay(t *testing.T) {
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+	// Short enough the player can't walk off the first building
+	const ticks = 150
+	tick := g.TickDuration()
+	path := filepath.Join(t.TempDir(), "run.wgmr")
+
+	g.ChangeScene(&MainGameScene{Seed: 1234, RecordPath: path})
+	recorded := g.current.(*MainGameScene)
+	mapping := recorded.Player.Keyboard.Mapping
+	keyboard := &scriptedKeyboard{
+		spans: map[ebiten.Key][2]int{
+			mapping[components.InputKindMoveRight]: {0, 20},
+			mapping[components.InputKindJump]:      {10, 25},
+			mapping[components.InputKindShoot]:     {45, 55},
+			mapping[components.InputKindMoveLeft]:  {60, 75},
+		},
+	}
+	recorded.Player.InputMode = components.InputModeKeyboard
+	recorded.Player.Keyboard.Driver = keyboard
+
+	const fastTick = 90
+	for ; keyboard.tick < ticks; keyboard.tick++ {
+		if keyboard.tick == fastTick {
+			recorded.InputEnt.PressedDuration[components.InputKindFastGameSpeed] = 1
+		}
+		recorded.Update(tick, g)
+	}
+	elapsed := recorded.TimeElapsed
+	position := recorded.Player.Postion
+	state := recorded.Player.State
+	hp := recorded.Player.HP
+
+	// Closing saves it
+	g.Close()
+	run, err := replay.LoadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1234), run.Seed)
+	assert.Len(t, run.Frames, ticks)
+	assert.Equal(t, 11, run.Frames[10].PressedDuration[components.InputKindMoveRight])
+	assert.True(t, run.Frames[10].JustPressed[components.InputKindJump])
+	assert.True(t, run.Frames[fastTick].FastGameSpeed)
+	assert.False(t, run.Frames[fastTick+1].FastGameSpeed)
+	assert.Equal(t, g.TickRate(), run.TickRate)
+
+	g.ChangeScene(&MainGameScene{Replay: run})
+	replayed := g.current.(*MainGameScene)
+	assert.Equal(t, int64(1234), replayed.Seed, "seed should come from the replay")
+	for i := 0; i < ticks; i++ {
+		// Only the replay gets to speed things up or pause
+		replayed.InputEnt.PressedDuration[components.InputKindFastGameSpeed] = 1
+		replayed.InputEnt.JustPressed[components.InputKindPause] = true
+		replayed.Update(tick, g)
+	}
+	assert.Equal(t, replayed, g.current, "replay shouldn't pause")
+	assert.True(t, replayed.replayPlayer.Done())
+	assert.Equal(t, elapsed, replayed.TimeElapsed)
+	assert.Equal(t, position, replayed.Player.Postion, "replay should end up in the same place")
+	assert.Equal(t, state, replayed.Player.State)
+	assert.Equal(t, hp, replayed.Player.HP)
+	g.Close()
+}
+
+func TestSeedEntryScene(t *testing.T) {
+	t.Parallel()
+
+	s := &SeedEntryScene{}
+
+	s.Type([]rune("ab-c u!d"))
+	assert.Equal(t, "ABCD", s.Code, "dashes spaces and invalid chars should be dropped")
+
+	s.Backspace()
+	assert.Equal(t, "ABC", s.Code)
+
+	s.Type([]rune("ZZZZZZZZZZZZZZZZZZZZZZ"))
+	assert.Len(t, s.Code, maxSeedCodeLength)
+
+	assert.Nil(t, s.Submit(), "code too long for a seed")
+	assert.NotEmpty(t, s.errMessage)
+
+	s.Code = ""
+	s.Type([]rune(utility.EncodeSeed(42)))
+	next, ok := s.Submit().(*MainGameScene)
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), next.Seed)
+}
+
+func TestSceneStack(t *testing.T) {
+	t.Parallel()
+
+	g := &Game{}
+	g.SetTickRate(100)
+
+	bottom := &countingScene{}
+	g.ChangeScene(bottom)
+	assert.Equal(t, 1, g.SceneDepth())
+
+	overlay := &countingScene{transparent: true}
+	g.PushScene(overlay)
+	assert.True(t, overlay.started)
+	assert.True(t, bottom.covered)
+	assert.False(t, bottom.ended, "covered scenes should be kept")
+	assert.Equal(t, 2, g.SceneDepth())
+
+	g.Advance(10 * time.Millisecond)
+	assert.Len(t, overlay.updates, 1)
+	assert.Empty(t, bottom.updates, "covered scenes should not update")
+
+	screen := ebiten.NewImage(1, 1)
+	g.Draw(screen)
+	assert.Equal(t, 1, bottom.draws, "transparent scenes draw over the scene below")
+	assert.Equal(t, 1, overlay.draws)
+
+	opaque := &countingScene{}
+	g.PushScene(opaque)
+	g.Draw(screen)
+	assert.Equal(t, 1, bottom.draws, "opaque scenes hide everything below")
+	assert.Equal(t, 1, overlay.draws)
+	assert.Equal(t, 1, opaque.draws)
+
+	g.PopScene()
+	assert.True(t, opaque.ended)
+	g.PopScene()
+	assert.True(t, overlay.ended)
+	assert.False(t, bottom.covered)
+	assert.Equal(t, 1, g.SceneDepth())
+
+	g.Advance(10 * time.Millisecond)
+	assert.Len(t, bottom.updates, 1, "uncovered scenes update again")
+
+	assert.Panics(t, func() {
+		g.PopScene()
+	}, "nothing left to pop")
+
+	// Changing scene ends the whole stack
+	g.PushScene(overlay)
+	bottom.ended = false
+	overlay.ended = false
+	g.ChangeScene(&countingScene{})
+	assert.True(t, bottom.ended)
+	assert.True(t, overlay.ended)
+	assert.Equal(t, 1, g.SceneDepth())
+}
+
+func TestPauseScene(t *testing.T) {
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+
+	recordPath := filepath.Join(t.TempDir(), "run.wgmr")
+	mgs := &MainGameScene{Seed: 99, RecordPath: recordPath}
+	g.ChangeScene(mgs)
+
+	g.PushScene(&PauseScene{Target: mgs})
+	pause, ok := g.current.(*PauseScene)
+	assert.True(t, ok)
+
+	screen := ebiten.NewImage(windowWidth, windowHeight)
+	assert.NotPanics(t, func() {
+		g.Draw(screen)
+	})
+
+	pause.selected(g, pauseOptionResume)
+	assert.Equal(t, mgs, g.current, "resume should pop back to the game")
+
+	g.PushScene(&PauseScene{Target: mgs})
+	g.current.(*PauseScene).selected(g, pauseOptionRestart)
+	restarted, ok := g.current.(*MainGameScene)
+	assert.True(t, ok)
+	assert.NotEqual(t, mgs, restarted)
+	assert.Equal(t, int64(99), restarted.Seed, "restart should keep the seed")
+	assert.Equal(t, recordPath, restarted.RecordPath, "restart should keep recording")
+	assert.NotNil(t, restarted.Recording)
+	assert.Same(t, mgs.Recording, restarted.Recording)
+	assert.Equal(t, 1, g.SceneDepth())
+
+	g.PushScene(&PauseScene{Target: restarted})
+	g.current.(*PauseScene).selected(g, pauseOptionQuit)
+	_, ok = g.current.(*TitleScene)
+	assert.True(t, ok)
+	assert.Equal(t, 1, g.SceneDepth())
+	assert.FileExists(t, recordPath, "quitting the restarted run should save it")
+	g.current.End(g)
+}
+
+type testEnemy struct {
+	ecs.BasicEntity
+	*components.TransformComponent
+	*components.LifeComponent
+	*components.IdentityComponent
+}
+
+func TestMainGameSceneDeath(t *testing.T) {
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+
+	mgs := &MainGameScene{Seed: 77}
+	g.ChangeScene(mgs)
+	assert.Equal(t, int64(77), mgs.Stats.Seed)
+
+	// Stats
+	enemy := func(x float64) *testEnemy {
+		return &testEnemy{
+			BasicEntity:        ecs.NewBasic(),
+			TransformComponent: &components.TransformComponent{Postion: math.Vector2{X: x, Y: 100}},
+			LifeComponent:      &components.LifeComponent{},
+			IdentityComponent:  &components.IdentityComponent{Tags: []int{entity.TagEnemy}},
+		}
+	}
+	mgs.Events.Publish(EntityDied{Ent: enemy(500)})
+	mgs.Events.Publish(EntityDied{Ent: enemy(-600)})
+	mgs.Events.Publish(TokenCollected{Player: mgs.Player, Tag: entity.TagJumpToken})
+	mgs.Events.Dispatch()
+	assert.Equal(t, 1, mgs.Stats.Kills, "enemies dying off screen weren't killed")
+	assert.Equal(t, 1, mgs.Stats.Tokens)
+
+	mgs.ScrollingSpeed.X = -pixelsPerMetre
+	mgs.State = gameStateScrolling
+	mgs.Update(time.Second, g)
+	assert.Greater(t, mgs.Stats.Distance, 0.9, "about a metre should have scrolled by")
+
+	// Dying, the player is invincible every other second so make sure it's not
+	mgs.Player.InvincibilityTimeRemaning = 0
+	mgs.Player.DamageEvents = append(mgs.Player.DamageEvents, &components.DamageEvent{Damage: mgs.Player.MaxHp})
+	mgs.Update(10*time.Millisecond, g)
+	assert.Equal(t, gameStateDying, mgs.State)
+	assert.Zero(t, mgs.ScrollingSpeed, "the city should stop")
+	assert.Equal(t, mgs.TimeElapsed, mgs.Stats.Time)
+
+	elapsed := mgs.TimeElapsed
+	mgs.Update(deathFreeze/2, g)
+	assert.Equal(t, elapsed, mgs.TimeElapsed, "everything should freeze")
+	assert.Equal(t, mgs, g.current)
+
+	mgs.Update(deathFreeze+deathFall, g)
+	over, ok := g.current.(*GameOverScene)
+	assert.True(t, ok, "game over should come after dying")
+	assert.Equal(t, int64(77), over.Stats.Seed)
+	assert.Equal(t, 1, over.Stats.Kills)
+	assert.Equal(t, 1, over.Stats.Tokens)
+	assert.Equal(t, elapsed, over.Stats.Time)
+	g.current.End(g)
+}
+
+func TestGameOverScene(t *testing.T) {
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+
+	stats := RunStats{
+		Seed:     99,
+		Distance: 123.4,
+		Kills:    5,
+		Tokens:   2,
+		Time:     90 * time.Second,
+	}
+
+	g.ChangeScene(&GameOverScene{Stats: stats})
+	over := g.current.(*GameOverScene)
+	assert.Contains(t, over.statLines(), fmt.Sprintf("SCORE %d", stats.Score()))
+	assert.Contains(t, over.statLines(), "DISTANCE 123M")
+	assert.Contains(t, over.statLines(), "TIME 1m30s")
+	assert.Contains(t, over.statLines(), "SEED "+utility.EncodeSeed(99))
+
+	screen := ebiten.NewImage(windowWidth, windowHeight)
+	assert.NotPanics(t, func() {
+		g.Draw(screen)
+	})
+
+	over.selected(g, gameOverOptionRetry)
+	retry, ok := g.current.(*MainGameScene)
+	assert.True(t, ok)
+	assert.Equal(t, int64(99), retry.Seed, "retry should keep the seed")
+
+	g.ChangeScene(&GameOverScene{Stats: stats})
+	g.current.(*GameOverScene).selected(g, gameOverOptionNewRun)
+	newRun, ok := g.current.(*MainGameScene)
+	assert.True(t, ok)
+	assert.NotEqual(t, int64(99), newRun.Seed, "a new run should get a new seed")
+
+	g.ChangeScene(&GameOverScene{Stats: stats})
+	g.current.(*GameOverScene).selected(g, gameOverOptionTitle)
+	_, ok = g.current.(*TitleScene)
+	assert.True(t, ok)
+	g.current.End(g)
+}
+
+func TestScoreSystem(t *testing.T) {
+	t.Parallel()
+
+	w := &ecs.World{}
+	bus := CreateEventBus()
+	AddSystem(w, CreateEventSystem(bus))
+
+	mgs := &MainGameScene{
+		ScrollingSpeed: math.Vector2{X: -pixelsPerMetre * 10},
+	}
+	AddSystem(w, CreateScoreSystem(mgs))
+
+	w.Update(1)
+	assert.Equal(t, float64(10), mgs.Stats.Distance)
+	assert.Equal(t, 10*pointsPerMetre, mgs.Stats.Score())
+	mgs.ScrollingSpeed = math.Vector2{}
+
+	enemy := func(x float64) *testEnemy {
+		return &testEnemy{
+			BasicEntity:        ecs.NewBasic(),
+			TransformComponent: &components.TransformComponent{Postion: math.Vector2{X: x, Y: 100}},
+			LifeComponent:      &components.LifeComponent{},
+			IdentityComponent:  &components.IdentityComponent{Tags: []int{entity.TagEnemy}},
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(EntityDied{Ent: enemy(500)})
+	}
+	w.Update(0)
+	assert.Equal(t, 3, mgs.Stats.Kills)
+	assert.Equal(t, pointsPerKill*(1+2+3), mgs.Stats.Points, "each kill in a row should be worth more")
+	assert.Equal(t, 4, mgs.Stats.Multiplier())
+
+	bus.Publish(EntityDied{Ent: enemy(-600)})
+	bus.Publish(TokenCollected{Tag: entity.TagSpeedToken})
+	w.Update(0)
+	assert.Equal(t, 3, mgs.Stats.Kills, "scrolling off isn't a kill")
+	assert.Equal(t, 1, mgs.Stats.Tokens)
+	assert.Equal(t, pointsPerKill*6+pointsPerToken, mgs.Stats.Points)
+	assert.Equal(t, "x4 "+fmt.Sprintf("%08d", mgs.Stats.Score()), scoreText(mgs.Stats))
+
+	bus.Publish(EntityDamaged{Ent: enemy(500), Damage: 1})
+	w.Update(0)
+	assert.Equal(t, 4, mgs.Stats.Multiplier(), "only the player getting hurt ends a streak")
+
+	bus.Publish(EntityDamaged{Ent: entity.CreatePlayer(), Damage: 1})
+	bus.Publish(EntityDied{Ent: enemy(500)})
+	w.Update(0)
+	assert.Equal(t, pointsPerKill*7+pointsPerToken, mgs.Stats.Points, "getting hurt should reset the multiplier")
+	assert.Equal(t, fmt.Sprintf("x2 %08d", mgs.Stats.Score()), scoreText(mgs.Stats))
+
+	mgs.Stats.Combo = 20
+	assert.Equal(t, maxMultiplier, mgs.Stats.Multiplier())
+
+	mgs.Stats = RunStats{}
+	assert.Equal(t, "00000000", scoreText(mgs.Stats), "no multiplier without a streak")
+}
+
+func TestNameEntry(t *testing.T) {
+	t.Parallel()
+
+	entry := createNameEntry()
+	assert.Equal(t, "AAA", entry.name())
+
+	entry.change(-1)
+	assert.Equal(t, "ZAA", entry.name(), "letters should wrap around")
+	assert.False(t, entry.next())
+
+	entry.change(2)
+	entry.change(1)
+	assert.Equal(t, "ZDA", entry.name())
+	assert.False(t, entry.next())
+
+	entry.change(26)
+	assert.Equal(t, "ZDA", entry.name())
+	assert.True(t, entry.next(), "done after the last letter")
+}
+
+func TestGameOverSceneHighScore(t *testing.T) {
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+
+	table := &scores.Table{}
+	for i := 0; i < scores.MaxEntries; i++ {
+		table.Add(scores.Entry{Name: "OLD", Score: (i + 1) * 1000})
+	}
+	storage := &scores.MemoryStorage{}
+	storage.Save(table)
+	g.SetScores(storage)
+
+	g.ChangeScene(&GameOverScene{Stats: RunStats{Seed: 5, Distance: 10}})
+	over := g.current.(*GameOverScene)
+	assert.Nil(t, over.entry, "a low score shouldn't get a name")
+	assert.Equal(t, -1, over.rank)
+
+	g.ChangeScene(&GameOverScene{Stats: RunStats{Seed: 5, Distance: 12.5, Points: 4900}})
+	over = g.current.(*GameOverScene)
+	assert.NotNil(t, over.entry, "a high score should get a name")
+
+	screen := ebiten.NewImage(windowWidth, windowHeight)
+	assert.NotPanics(t, func() {
+		g.Draw(screen)
+	})
+
+	over.entry.change(-1)
+	over.entry.next()
+	over.entry.change(7)
+	over.saveScore(g)
+	assert.Nil(t, over.entry)
+	assert.Equal(t, 5, over.rank, "5025 should go under 6000")
+
+	saved, err := storage.Load()
+	assert.NoError(t, err)
+	assert.Len(t, saved.Entries, scores.MaxEntries)
+	entry := saved.Entries[5]
+	assert.Equal(t, "ZHA", entry.Name)
+	assert.Equal(t, 5025, entry.Score)
+	assert.Equal(t, 12.5, entry.Distance)
+	assert.Equal(t, int64(5), entry.Seed)
+	assert.False(t, entry.Date.IsZero())
+	assert.Equal(t, 2000, saved.Entries[len(saved.Entries)-1].Score, "the lowest should fall off")
+
+	g.ChangeScene(&LeaderboardScene{})
+	leaderboard := g.current.(*LeaderboardScene)
+	rows := leaderboard.rows()
+	assert.Len(t, rows, scores.MaxEntries)
+	assert.Contains(t, rows[5], " 6 ZHA 00005025    12M "+utility.EncodeSeed(5))
+	assert.NotPanics(t, func() {
+		g.Draw(screen)
+	})
+	g.current.End(g)
+}
+
+// brokenScores is a scores file that can't be read
+type brokenScores struct {
+	saves int
+}
+
+func (b *brokenScores) Load() (*scores.Table, error) {
+	return nil, scores.ErrUnsupportedVersion
+}
+
+func (b *brokenScores) Save(*scores.Table) error {
+	b.saves++
+	return nil
+}
+
+func TestGameOverSceneBrokenScores(t *testing.T) {
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+	storage := &brokenScores{}
+	g.SetScores(storage)
+
+	g.ChangeScene(&GameOverScene{Stats: RunStats{Seed: 5, Points: 5000}})
+	over := g.current.(*GameOverScene)
+	assert.Nil(t, over.entry, "shouldn't ask for a name when it can't be saved")
+	assert.Contains(t, over.message, "UNABLE TO LOAD SCORES")
+
+	over.saveScore(g)
+	assert.Zero(t, storage.saves, "the scores file should never be written over")
+	assert.Equal(t, -1, over.rank)
+
+	screen := ebiten.NewImage(windowWidth, windowHeight)
+	assert.NotPanics(t, func() {
+		g.Draw(screen)
+	})
+	g.current.End(g)
+}
+
+type transitionRequestScene struct {
+	countingScene
+	transition Transition
+}
+
+func (s *transitionRequestScene) Transition() Transition {
+	return s.transition
+}
+
+func TestTransitions(t *testing.T) {
+	g := &Game{}
+	g.SetTickRate(10)
+
+	g.ChangeScene(&countingScene{})
+	assert.False(t, g.Transitioning(), "nothing to transition from")
+
+	screen := ebiten.NewImage(windowWidth, windowHeight)
+
+	for _, transitionType := range []TransitionType{TransitionFade, TransitionWipe, TransitionIris} {
+		next := &transitionRequestScene{
+			transition: Transition{
+				Type:     transitionType,
+				Duration: 500 * time.Millisecond,
+				Color:    color.Black,
+			},
+		}
+		g.ChangeScene(next)
+		assert.True(t, g.Transitioning())
+		assert.Equal(t, transitionType, g.transition.Type, "scene should get the transition it asked for")
+		assert.Equal(t, Scene(next), g.current, "new scene starts straight away")
+
+		for i := 0; i < 5; i++ {
+			assert.True(t, g.Transitioning())
+			assert.NotPanics(t, func() {
+				g.Draw(screen)
+			})
+			g.Advance(100 * time.Millisecond)
+		}
+		assert.False(t, g.Transitioning())
+		assert.Len(t, next.updates, 5, "new scene keeps updating during the transition")
+		assert.Greater(t, next.draws, 0)
+	}
+
+	g.ChangeSceneWith(&countingScene{}, Transition{Type: TransitionNone})
+	assert.False(t, g.Transitioning())
+
+	// Fading without a colour goes through black
+	g.ChangeSceneWith(&countingScene{}, Transition{Type: TransitionFade, Duration: time.Second})
+	assert.NotPanics(t, func() {
+		g.Draw(screen)
+	})
+	assert.Equal(t, color.Black, g.transition.color())
+	g.ChangeSceneWith(&countingScene{}, Transition{Type: TransitionNone})
+
+	// Changing mid transition starts a fresh one
+	g.ChangeScene(&countingScene{})
+	g.Advance(100 * time.Millisecond)
+	g.ChangeSceneWith(&countingScene{}, Transition{Type: TransitionWipe, Duration: time.Second})
+	assert.Equal(t, TransitionWipe, g.transition.Type)
+	assert.Zero(t, g.transition.elapsed)
+}
+
+func TestLoadKaraokeChart(t *testing.T) {
+	t.Parallel()
+
+	session, err := LoadKaraokeSession(assets.LoadKaraoke(assets.KaraokeTest))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, session.Inputs)
+
+	_, err = LoadKaraokeSession([]byte(`{"inputs": [], "backgrounds": []}`))
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+
+	music, err := ioutil.ReadFile(filepath.Join("..", "assets", "karaoke", "music", "pdRockBackground.mp3"))
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "music.mp3"), music, 0644))
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	buffer := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(buffer, img))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "background.png"), buffer.Bytes(), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "background.psd"), []byte("8BPS"), 0644))
+
+	writeChart := func(name, background, sound string) string {
+		chart := fmt.Sprintf(`{
+			"music": "music.mp3",
+			"inputs": [
+				{"duration": 3000, "sound": "A"},
+				{"duration": 2000, "start_off": -500, "sound": %q}
+			],
+			"backgrounds": [{"duration": 10000, "fade_in": 3000, "image": %q}]
+		}`, sound, background)
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(chart), 0644))
+		return path
+	}
+
+	session, err = LoadKaraokeChart(writeChart("good.json", "background.png", "Y"))
+	assert.NoError(t, err)
+	assert.Len(t, session.Inputs, 2)
+	assert.Equal(t, DurationMil(2500*time.Millisecond), session.Inputs[1].StartTime)
+	assert.Equal(t, DurationMil(3*time.Second), session.Backgrounds[0].FadeIn)
+	assert.Greater(t, session.SampleRate, 0)
+	assert.NotNil(t, loadImage(session.Backgrounds[0].Image))
+
+	_, err = LoadKaraokeChart(writeChart("psd.json", "background.psd", "Y"))
+	assert.Error(t, err, "only png and jpeg backgrounds are supported")
+
+	_, err = LoadKaraokeChart(writeChart("sound.json", "background.png", "Z"))
+	assert.Error(t, err)
+
+	_, err = LoadKaraokeChart(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestOptionsScene(t *testing.T) {
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+	path := filepath.Join(t.TempDir(), "settings.json")
+	g.settingsPath = path
+
+	options := &OptionsScene{}
+	g.ChangeScene(options)
+
+	rowIdx := func(rowType optionsRowType, kind components.InputKind) int {
+		for i, row := range options.rows {
+			if row.rowType == rowType && row.kind == kind {
+				return i
+			}
+		}
+		t.Fatalf("missing row %v %v", rowType, kind)
+		return -1
+	}
+
+	options.selectedIdx = rowIdx(optionsRowVolume, 0)
+	options.adjust(-1)
+	options.adjust(-1)
+	assert.InDelta(t, 0.8, options.Settings.Volume, 0.001)
+	assert.Equal(t, float64(1), g.Volume(), "nothing changes until saved")
+
+	options.selectedIdx = rowIdx(optionsRowInputMode, 0)
+	options.adjust(1)
+	assert.Equal(t, components.InputModeGamepad, options.Settings.InputMode)
+
+	options.selectedIdx = rowIdx(optionsRowKey, components.InputKindJump)
+	options.activate(g)
+	assert.True(t, options.TakesTextInput())
+	options.bindKey(ebiten.KeySpace)
+	assert.False(t, options.TakesTextInput())
+	assert.Equal(t, ebiten.KeySpace, options.Settings.Keyboard[components.InputKindJump])
+
+	options.activate(g)
+	options.bindKey(ebiten.KeyEscape)
+	assert.False(t, options.TakesTextInput(), "escape should stop waiting")
+	assert.Equal(t, ebiten.KeySpace, options.Settings.Keyboard[components.InputKindJump], "escape cancels instead of being bound")
+
+	options.selectedIdx = rowIdx(optionsRowButton, components.InputKindShoot)
+	options.activate(g)
+	options.bindButton(5)
+	assert.Equal(t, ebiten.GamepadButton(5), options.Settings.Gamepad[components.InputKindShoot])
+
+	for _, row := range options.rows {
+		assert.NotPanics(t, func() {
+			options.rowText(row)
+		})
+	}
+
+	options.selectedIdx = rowIdx(optionsRowSave, 0)
+	options.activate(g)
+	assert.Equal(t, "SAVED", options.message)
+	assert.InDelta(t, 0.8, g.Volume(), 0.001)
+
+	saved, err := settings.LoadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ebiten.KeySpace, saved.Keyboard[components.InputKindJump])
+	assert.Equal(t, components.InputModeGamepad, saved.InputMode)
+
+	// New scenes pick up the bindings
+	mgs := &MainGameScene{}
+	g.ChangeScene(mgs)
+	assert.Equal(t, ebiten.KeySpace, mgs.Player.Keyboard.Mapping[components.InputKindJump])
+	assert.Equal(t, components.InputModeGamepad, mgs.Player.InputMode)
+
+	g.ChangeScene(options)
+	options.selectedIdx = rowIdx(optionsRowReset, 0)
+	options.activate(g)
+	assert.Equal(t, settings.Default(), options.Settings)
+
+	options.selectedIdx = rowIdx(optionsRowBack, 0)
+	options.activate(g)
+	_, ok := g.current.(*TitleScene)
+	assert.True(t, ok)
+	g.current.End(g)
+}
+
+func TestSpeedTokenBoost(t *testing.T) {
+	t.Parallel()
+
+	w := &ecs.World{}
+	mainGameScene := &MainGameScene{
+		Rand:           rand.New(rand.NewSource(1)),
+		World:          w,
+		ScrollingSpeed: math.Vector2{X: xStartScrollSpeed},
+		State:          gameStateScrolling,
+	}
+
+	var timerable *Timerable
+	AddSystemInterface(w, CreateTimerSystem(), timerable, nil)
+	var playerable *Playerable
+	AddSystemInterface(w, CreatePlayerSystem(mainGameScene), playerable, nil)
+
+	player := entity.CreatePlayer()
+	w.AddEntity(player)
+
+	player.Collisions = components.CollisionEvents{{Tags: []int{entity.TagSpeedToken}}}
+	w.Update(0.1)
+	player.Collisions = nil
+	assert.Less(t, mainGameScene.ScrollingSpeed.X, xStartScrollSpeed, "token should speed up scrolling")
+
+	// Nothing happens while the world isn't updated e.g. when paused
+	time.Sleep(10 * time.Millisecond)
+	assert.Less(t, mainGameScene.ScrollingSpeed.X, xStartScrollSpeed)
+
+	for elapsed := time.Duration(0); elapsed < speedBoostTime; elapsed += 100 * time.Millisecond {
+		w.Update(0.1)
+	}
+	assert.Equal(t, xStartScrollSpeed, mainGameScene.ScrollingSpeed.X, "boost should wear off")
+}
+
+func TestPlayerStomp(t *testing.T) {
+	t.Parallel()
+
+	w := &ecs.World{}
+	bus := CreateEventBus()
+	AddSystem(w, CreateEventSystem(bus))
+	mainGameScene := &MainGameScene{
+		Rand:           rand.New(rand.NewSource(1)),
+		World:          w,
+		ScrollingSpeed: math.Vector2{X: xStartScrollSpeed},
+		State:          gameStateScrolling,
+	}
+
+	var playerable *Playerable
+	AddSystemInterface(w, CreatePlayerSystem(mainGameScene), playerable, nil)
+
+	var stomped []uint64
+	bus.Subscribe(EventKindEnemyStomped, func(e Event) {
+		stomped = append(stomped, e.(EnemyStomped).Enemy)
+	})
+
+	player := entity.CreatePlayer()
+	player.JumpPower = startingPlayerJumpPower
+	w.AddEntity(player)
+
+	// Running into the side isn't a stomp
+	player.Collisions = components.CollisionEvents{{ID: 7, Tags: []int{entity.TagEnemy}, Normal: math.Vector2{X: -1}}}
+	w.Update(0.1)
+	assert.Equal(t, components.MainGamePlayerStateFlying, player.State)
+	assert.Empty(t, stomped)
+
+	player.Collisions = components.CollisionEvents{{ID: 7, Tags: []int{entity.TagEnemy}, Normal: math.Vector2{Y: -1}}}
+	w.Update(0.1)
+	player.Collisions = nil
+	assert.Equal(t, components.MainGamePlayerStateBouncing, player.State)
+	assert.Equal(t, []uint64{7}, stomped)
+	assert.Equal(t, startingPlayerJumpPower*stompBounce, player.JumpPowerRemaning, "bounce should scale with jump power")
+
+	w.Update(0.1)
+	assert.Less(t, player.Vel.Y, float64(0), "should be bouncing up")
+
+	for i := 0; i < 100 && player.State == components.MainGamePlayerStateBouncing; i++ {
+		w.Update(0.1)
+	}
+	assert.Equal(t, components.MainGamePlayerStateFlying, player.State, "bounce should run out")
+}
+
+func TestSystemOrder(t *testing.T) {
+	t.Parallel()
+
+	m := &MainGameScene{
+		World:  &ecs.World{},
+		Events: CreateEventBus(),
+		Space:  resolv.NewSpace(),
+	}
+	m.addSystems(&Game{})
+	systems := m.World.Systems()
+	order := systemNames(systems)
+	assert.Equal(t, "EventSystem", order[len(order)-1])
+
+	position := make(map[string]int)
+	for i, name := range order {
+		position[name] = i
+	}
+	assert.Less(t, position["InputSystem"], position["PlayerSystem"])
+	assert.Less(t, position["ResolvSystem"], position["VelocitySystem"])
+	assert.Less(t, position["ResolvSystem"], position["LifeSystem"])
+
+	// Priorities have to be unique since ecs doesn't sort stably
+	seen := make(map[int]bool)
+	for _, system := range systems {
+		priority := system.(ecs.Prioritizer).Priority()
+		assert.False(t, seen[priority], systemName(system))
+		seen[priority] = true
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, DumpSystemOrder(buf))
+	assert.Contains(t, buf.String(), " 1 InputSystem before PlayerSystem")
+	assert.Contains(t, buf.String(), "EventSystem last")
+
+	// Only systems in the world count, the rest go in the order they're added
+	w := &ecs.World{}
+	var velocityable *Velocityable
+	AddSystemInterface(w, CreateVelocitySystem(resolv.NewSpace()), velocityable, nil)
+	AddSystem(w, CreateEventSystem(CreateEventBus()))
+	var timerable *Timerable
+	AddSystemInterface(w, CreateTimerSystem(), timerable, nil)
+	assert.Equal(t, []string{"VelocitySystem", "TimerSystem", "EventSystem"}, systemNames(w.Systems()))
+
+	var resolvable *Resolvable
+	AddSystemInterface(w, CreateResolvSystem(resolv.NewSpace(), nil), resolvable, nil)
+	assert.Equal(t, []string{"TimerSystem", "ResolvSystem", "VelocitySystem", "EventSystem"}, systemNames(w.Systems()))
+
+	assert.Panics(t, func() {
+		AddSystemInterface(w, CreateTimerSystem(), timerable, nil)
+	}, "can't be added twice")
+
+	// Ties keep the order given
+	got, err := resolveSystemOrder([]systemNode{
+		{name: "A"},
+		{name: "B"},
+		{name: "C", before: []string{"A"}},
+		{name: "D", last: true},
+		{name: "E", after: []string{"Dragon"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"B", "C", "A", "E", "D"}, got)
+
+	_, err = resolveSystemOrder([]systemNode{
+		{name: "A", after: []string{"C"}},
+		{name: "B", after: []string{"A"}},
+		{name: "C", after: []string{"B"}},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "A -> B -> C -> A")
+	}
+
+	_, err = resolveSystemOrder([]systemNode{{name: "A"}, {name: "A"}})
+	assert.Error(t, err)
+}
+
+func TestConsole(t *testing.T) {
+	g := &Game{
+		audioCtx: audio.CurrentContext(),
+	}
+	c := &g.console
+
+	c.Toggle()
+	assert.True(t, c.Open)
+	c.Type([]rune("`gravity 100"))
+	assert.Equal(t, "gravity 100", c.Input, "toggle key shouldn't be typed")
+	c.Backspace()
+	c.Submit(g)
+	assert.Empty(t, c.Input)
+	assert.Equal(t, "> gravity 10", c.Output[0])
+	assert.Contains(t, c.Output[1], errConsoleNoMainGame.Error())
+
+	_, err := c.Exec(g, "dragon")
+	assert.Error(t, err)
+
+	result, err := c.Exec(g, "help")
+	assert.NoError(t, err)
+	assert.Contains(t, result, "spawn <prefab>")
+
+	m := &MainGameScene{Seed: 1}
+	g.ChangeSceneWith(m, Transition{Type: TransitionNone})
+
+	_, err = c.Exec(g, "gravity 10")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(10), m.Gravity)
+
+	_, err = c.Exec(g, "gravity fast")
+	assert.Error(t, err)
+
+	_, err = c.Exec(g, "scroll -5 2")
+	assert.NoError(t, err)
+	assert.Equal(t, math.Vector2{X: -5, Y: 2}, m.ScrollingSpeed)
+
+	_, err = c.Exec(g, "hp 1")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), m.Player.HP)
+
+	_, err = c.Exec(g, "give jump")
+	assert.NoError(t, err)
+	assert.True(t, m.Player.Collisions.CollidingWith(entity.TagJumpToken))
+
+	_, err = c.Exec(g, "collision")
+	assert.NoError(t, err)
+	assert.True(t, worldResolvSystem(m.World).OverlayEnabled)
+
+	_, err = c.Exec(g, "spawn biscuitEnemy 100 200")
+	assert.NoError(t, err)
+	_, err = c.Exec(g, "spawn dragon 100 200")
+	assert.Error(t, err)
+
+	result, err = c.Exec(g, "ents enemy")
+	assert.NoError(t, err)
+	assert.Contains(t, result, "at 100,200")
+	_, err = c.Exec(g, "ents dragon")
+	assert.Error(t, err)
+
+	_, err = c.Exec(g, fmt.Sprintf("inspect %d", m.Player.ID()))
+	assert.NoError(t, err)
+	assert.Equal(t, m.Player.ID(), worldInspectorSystem(m.World).Selected)
+	assert.True(t, worldInspectorSystem(m.World).Enabled)
+	_, err = c.Exec(g, "inspect 0")
+	assert.Error(t, err)
+
+	for i := 0; i < consoleMaxLines*2; i++ {
+		c.print("line")
+	}
+	assert.Len(t, c.Output, consoleMaxLines)
+	_, err = c.Exec(g, "clear")
+	assert.NoError(t, err)
+	assert.Empty(t, c.Output)
+
+	_, err = c.Exec(g, "scene title")
+	assert.NoError(t, err)
+	_, ok := g.current.(*TitleScene)
+	assert.True(t, ok)
+	assert.Nil(t, g.mainGameScene())
+	g.current.End(g)
+}
+
+func TestProfiler(t *testing.T) {
+	now := time.Unix(0, 0)
+	p := &Profiler{
+		now: func() time.Time {
+			now = now.Add(time.Millisecond)
+			return now
+		},
+	}
+
+	p.Measure("off", func() {})
+	assert.Empty(t, p.Samples(), "nothing should be recorded while disabled")
+
+	p.SetEnabled(true)
+	p.Measure("outer", func() {
+		p.Measure("inner", func() {})
+	})
+	assert.Equal(t, []ProfileSample{
+		{Name: "inner", Depth: 1, Start: 2 * time.Millisecond, Duration: time.Millisecond},
+		{Name: "outer", Depth: 0, Start: time.Millisecond, Duration: 3 * time.Millisecond},
+	}, p.Samples())
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, p.WriteCSV(buf))
+	assert.Equal(t, "frame,name,depth,start_us,duration_us\n0,outer,0,1000,3000\n0,inner,1,2000,1000\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, p.WriteChromeTrace(buf))
+	trace := struct {
+		TraceEvents []chromeTraceEvent `json:"traceEvents"`
+	}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &trace))
+	if assert.Len(t, trace.TraceEvents, 2) {
+		assert.Equal(t, "outer", trace.TraceEvents[0].Name)
+		assert.Equal(t, "X", trace.TraceEvents[0].Phase)
+		assert.Equal(t, float64(1000), trace.TraceEvents[0].Time)
+		assert.Equal(t, float64(3000), trace.TraceEvents[0].Duration)
+	}
+
+	dir := t.TempDir()
+	assert.NoError(t, p.Save(filepath.Join(dir, "run.csv")))
+	assert.NoError(t, p.Save(filepath.Join(dir, "run.json")))
+	assert.Error(t, p.Save(filepath.Join(dir, "run.txt")))
+
+	for i := 0; i < profilerMaxFrames+10; i++ {
+		p.NextFrame()
+		p.Measure("frame", func() {})
+	}
+	assert.Len(t, p.Samples(), profilerMaxFrames, "old frames should be dropped")
+
+	// Every system gets timed
+	old := profiler
+	profiler = p
+	defer func() {
+		profiler = old
+	}()
+	p.Reset()
+
+	w := &ecs.World{}
+	AddSystem(w, CreateEventSystem(CreateEventBus()))
+	var imageRenderable *ImageRenderable
+	AddSystemInterface(w, CreateImageRenderSystem(), imageRenderable, nil)
+
+	updateWorld(w, 0.1)
+	renderWorld(w)
+
+	var names []string
+	for _, sample := range p.Samples() {
+		names = append(names, sample.Name)
+	}
+	assert.Equal(t, []string{
+		"ImageRenderSystem.Update",
+		"EventSystem.Update",
+		"ImageRenderSystem.Render",
+		"RenderCmds.Sort",
+	}, names)
+
+	p.Draw(ebiten.NewImage(windowWidth, windowHeight))
+}
+
+func TestAssetReloadSystem(t *testing.T) {
+	t.Parallel()
+
+	w := &ecs.World{}
+	s := CreateAssetReloadSystem()
+	var imageReloadable *ImageReloadable
+	var tileImageReloadable *TileImageReloadable
+	AddSystemInterface(w, s, []interface{}{imageReloadable, tileImageReloadable}, nil)
+
+	old := ebiten.NewImage(1, 1)
+	other := ebiten.NewImage(1, 1)
+	imageEnt := &struct {
+		ecs.BasicEntity
+		*components.ImageComponent
+	}{
+		BasicEntity:    ecs.NewBasic(),
+		ImageComponent: &components.ImageComponent{Image: old},
+	}
+	w.AddEntity(imageEnt)
+
+	otherEnt := &struct {
+		ecs.BasicEntity
+		*components.ImageComponent
+	}{
+		BasicEntity:    ecs.NewBasic(),
+		ImageComponent: &components.ImageComponent{Image: other},
+	}
+	w.AddEntity(otherEnt)
+
+	tileEnt := &struct {
+		ecs.BasicEntity
+		*components.TileImageComponent
+	}{
+		BasicEntity: ecs.NewBasic(),
+		TileImageComponent: &components.TileImageComponent{
+			TileMap: components.CreateTileMap(1, 1, old, 1),
+		},
+	}
+	w.AddEntity(tileEnt)
+
+	// Reloaded twice before it got swapped in
+	first := ebiten.NewImage(2, 2)
+	second := ebiten.NewImage(3, 3)
+	s.swap([]assets.ReloadedImage{
+		{Old: old, New: first},
+		{Old: first, New: second},
+	})
+	assert.Equal(t, second, imageEnt.Image)
+	assert.Equal(t, second, tileEnt.TileMap.TilesImg)
+	assert.Equal(t, other, otherEnt.Image, "images not reloaded should be left alone")
+
+	w.RemoveEntity(imageEnt.BasicEntity)
+	assert.NotContains(t, s.imageEnts, imageEnt.ID())
+
+	// Nothing ever reloads without the dev tag
+	w.Update(1)
+	assert.Equal(t, second, tileEnt.TileMap.TilesImg)
+}
+
+func TestSweepRect(t *testing.T) {
+	t.Parallel()
+
+	wall := resolv.NewRectangle(10, 0, 2, 10)
+
+	testCases := []struct {
+		name   string
+		rect   *resolv.Rectangle
+		delta  math.Vector2
+		hit    bool
+		time   float64
+		normal math.Vector2
+	}{
+		{name: "through", rect: resolv.NewRectangle(0, 0, 2, 2), delta: math.Vector2{X: 20}, hit: true, time: 0.4, normal: math.Vector2{X: -1}},
+		{name: "backwards", rect: resolv.NewRectangle(20, 0, 2, 2), delta: math.Vector2{X: -20}, hit: true, time: 0.4, normal: math.Vector2{X: 1}},
+		{name: "short", rect: resolv.NewRectangle(0, 0, 2, 2), delta: math.Vector2{X: 5}},
+		{name: "only touches", rect: resolv.NewRectangle(0, 0, 2, 2), delta: math.Vector2{X: 8}},
+		{name: "above", rect: resolv.NewRectangle(0, -5, 2, 2), delta: math.Vector2{X: 20}},
+		{name: "diagonal", rect: resolv.NewRectangle(0, -10, 2, 2), delta: math.Vector2{X: 20, Y: 20}, hit: true, time: 0.4, normal: math.Vector2{Y: -1}},
+		{name: "landing", rect: resolv.NewRectangle(10, -10, 2, 2), delta: math.Vector2{Y: 20}, hit: true, time: 0.4, normal: math.Vector2{Y: -1}},
+		{name: "inside", rect: resolv.NewRectangle(10, 0, 2, 2), delta: math.Vector2{X: 1}, hit: true, time: -2},
+		{name: "still", rect: resolv.NewRectangle(0, 0, 2, 2)},
+	}
+
+	for _, testCase := range testCases {
+		time, normal, hit := sweepRect(testCase.rect, testCase.delta, wall)
+		assert.Equalf(t, testCase.hit, hit, "case: %s", testCase.name)
+		if testCase.hit {
+			assert.InDeltaf(t, testCase.time, time, 0.0001, "case: %s", testCase.name)
+		}
+		if testCase.hit && testCase.time >= 0 {
+			assert.Equalf(t, testCase.normal, normal, "case: %s", testCase.name)
+		}
+	}
+}
+
+func TestContact(t *testing.T) {
+	t.Parallel()
+
+	block := resolv.NewRectangle(0, 0, 10, 10)
+
+	testCases := []struct {
+		name   string
+		rect   *resolv.Rectangle
+		normal math.Vector2
+		depth  float64
+	}{
+		{name: "standing on", rect: resolv.NewRectangle(2, -4, 4, 4), normal: math.Vector2{Y: -1}, depth: 0},
+		{name: "sunk into top", rect: resolv.NewRectangle(2, -3, 4, 4), normal: math.Vector2{Y: -1}, depth: 1},
+		{name: "under", rect: resolv.NewRectangle(2, 8, 4, 4), normal: math.Vector2{Y: 1}, depth: 2},
+		{name: "left side", rect: resolv.NewRectangle(-3, 2, 4, 4), normal: math.Vector2{X: -1}, depth: 1},
+		{name: "right side", rect: resolv.NewRectangle(9, 2, 4, 4), normal: math.Vector2{X: 1}, depth: 1},
+	}
+
+	for _, testCase := range testCases {
+		normal, depth := contact(testCase.rect, block)
+		assert.Equalf(t, testCase.normal, normal, "case: %s", testCase.name)
+		assert.InDeltaf(t, testCase.depth, depth, 0.0001, "case: %s", testCase.name)
+	}
+}
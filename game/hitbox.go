@@ -0,0 +1,110 @@
+package game
+
+import (
+	"time"
+
+	"github.com/SolarLune/resolv"
+	"github.com/sardap/walk-good-maybe-hd/assets"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+)
+
+type hitboxed interface {
+	components.TransformFace
+	components.CollisionFace
+}
+
+// frame is which frame of its animation ent is on
+func frame(ent interface{}) int {
+	tileImage, ok := ent.(components.TileImageFace)
+	if !ok {
+		return 0
+	}
+
+	tileMap := tileImage.GetTileImageComponent().TileMap
+	if tileMap == nil || len(tileMap.Map) == 0 {
+		return 0
+	}
+
+	return int(tileMap.Map[0])
+}
+
+// flippedX is if ent is drawn facing the other way
+func flippedX(ent interface{}) bool {
+	if tileImage, ok := ent.(components.TileImageFace); ok {
+		if tileMap := tileImage.GetTileImageComponent().TileMap; tileMap != nil {
+			return tileMap.Options.InvertX
+		}
+	}
+
+	if image, ok := ent.(components.ImageFace); ok {
+		return image.GetImageComponent().Options.InvertX
+	}
+
+	return false
+}
+
+// hitboxRects is ent's hitbox right now, all of it without one
+func hitboxRects(ent hitboxed) []components.HitboxRect {
+	if hitbox := ent.GetCollisionComponent().Hitbox; hitbox != nil {
+		return hitbox.At(frame(ent))
+	}
+
+	size := ent.GetTransformComponent().Size
+	return []components.HitboxRect{{W: size.X, H: size.Y}}
+}
+
+// changeAnimeImage swaps ent's animation to asset, if ent collides its hitbox
+// is swapped for the new image's too
+func changeAnimeImage(ent components.Animeable, asset interface{}, frameDuration time.Duration) {
+	img, _ := assets.LoadEbitenImage(asset)
+	components.ChangeAnimeImage(ent, img, frameDuration)
+
+	if col, ok := ent.(components.CollisionFace); ok {
+		col.GetCollisionComponent().Hitbox = entity.DefaultHitboxes().For(assets.Name(asset))
+	}
+}
+
+// layoutHitbox moves ent's parts to where its hitbox is, ResolvSystem makes
+// sure it has the right number of them
+func layoutHitbox(ent hitboxed) {
+	trans := ent.GetTransformComponent()
+	rects := hitboxRects(ent)
+	// Hitboxes are drawn facing right
+	flipped := flippedX(ent)
+
+	for i, part := range parts(ent) {
+		if i >= len(rects) {
+			break
+		}
+
+		x := rects[i].X
+		if flipped {
+			x = trans.Size.X - rects[i].X - rects[i].W
+		}
+
+		part.X = trans.Postion.X + x
+		part.Y = trans.Postion.Y + rects[i].Y
+		part.W = rects[i].W
+		part.H = rects[i].H
+	}
+}
+
+// parts are the rects in the space making up ent's hitbox
+func parts(ent components.CollisionFace) []*resolv.Rectangle {
+	shapes := ent.GetCollisionComponent().CollisionShapes
+	if shapes == nil {
+		return nil
+	}
+
+	result := make([]*resolv.Rectangle, 0, len(*shapes))
+	for _, shape := range *shapes {
+		result = append(result, shape.(*resolv.Rectangle))
+	}
+
+	return result
+}
+
+func grown(rect *resolv.Rectangle, by float64) *resolv.Rectangle {
+	return resolv.NewRectangle(rect.X-by, rect.Y-by, rect.W+by*2, rect.H+by*2)
+}
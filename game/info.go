@@ -1,11 +1,63 @@
-package game
-
-type gameState int
-
-const (
-	gameStateStarting gameState = iota
-	gameStateScrolling
-)
-
-type Info struct {
-}
+package game
+
+import (
+	"time"
+
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/entity"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+)
+
+type gameState int
+
+const (
+	gameStateStarting gameState = iota
+	gameStateScrolling
+	// gameStateDying is after the player died until the game over screen
+	gameStateDying
+)
+
+// pixelsPerMetre is how far the city scrolls for a metre of distance
+const pixelsPerMetre = 4 * scaleMultiplier
+
+type Info struct {
+}
+
+// RunStats is how a run of the main game went
+type RunStats struct {
+	Seed int64
+	// Distance is in metres
+	Distance float64
+	Kills    int
+	Tokens   int
+	Time     time.Duration
+	// Points are from kills and tokens, distance is added on in Score
+	Points int
+	// Combo is kills in a row without the player getting hurt
+	Combo int
+}
+
+func (r RunStats) Score() int {
+	return int(r.Distance*pointsPerMetre) + r.Points
+}
+
+// Multiplier is what the next kill is worth times pointsPerKill
+func (r RunStats) Multiplier() int {
+	if r.Combo+1 > maxMultiplier {
+		return maxMultiplier
+	}
+
+	return r.Combo + 1
+}
+
+// killed is if an enemy died where the player could see it, anywhere else it
+// scrolled off or fell into a kill box
+func killed(e EntityDied) bool {
+	identity, ok := e.Ent.(components.IdentityFace)
+	if !ok || !utility.ContainsInt(identity.GetIdentityComponent().Tags, entity.TagEnemy) {
+		return false
+	}
+
+	pos := e.Ent.GetTransformComponent().Postion
+	return pos.X >= 0 && pos.X <= windowWidth && pos.Y <= windowHeight
+}
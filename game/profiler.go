@@ -0,0 +1,331 @@
+package game
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+)
+
+const (
+	// About a minute at 60 FPS
+	profilerMaxFrames   = 3600
+	profilerGraphFrames = 250
+	profilerBarWidth    = 4
+	// Pixels per millisecond on the graph
+	profilerGraphScale = 20
+	profilerGraphTop   = 5
+)
+
+// profiler is package wide since scenes are drawn without the game
+var profiler = &Profiler{}
+
+type ProfileSample struct {
+	Frame int
+	Name  string
+	// Depth is how many measurements this one is inside of
+	Depth int
+	// Start is since the profiler was turned on
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// Profiler times systems, renders and scene starts while it's enabled keeping
+// the last profilerMaxFrames frames
+type Profiler struct {
+	Enabled bool
+	samples []ProfileSample
+	frame   int
+	depth   int
+	start   time.Time
+	// now is swapped out in tests
+	now  func() time.Time
+	font font.Face
+}
+
+func (p *Profiler) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+
+	return time.Now()
+}
+
+func (p *Profiler) SetEnabled(enabled bool) {
+	if enabled && p.start.IsZero() {
+		p.start = p.clock()
+	}
+	p.Enabled = enabled
+}
+
+func (p *Profiler) Reset() {
+	p.samples = nil
+	p.frame = 0
+	p.start = p.clock()
+}
+
+func (p *Profiler) Samples() []ProfileSample {
+	return p.samples
+}
+
+// NextFrame starts a new frame dropping any too old to keep
+func (p *Profiler) NextFrame() {
+	if !p.Enabled {
+		return
+	}
+
+	p.frame++
+	drop := 0
+	for drop < len(p.samples) && p.samples[drop].Frame <= p.frame-profilerMaxFrames {
+		drop++
+	}
+	p.samples = p.samples[drop:]
+}
+
+func (p *Profiler) Measure(name string, f func()) {
+	if !p.Enabled {
+		f()
+		return
+	}
+
+	start := p.clock()
+	p.depth++
+	f()
+	p.depth--
+
+	p.samples = append(p.samples, ProfileSample{
+		Frame:    p.frame,
+		Name:     name,
+		Depth:    p.depth,
+		Start:    start.Sub(p.start),
+		Duration: p.clock().Sub(start),
+	})
+}
+
+// sorted is the samples in the order they started
+func (p *Profiler) sorted() []ProfileSample {
+	result := append([]ProfileSample{}, p.samples...)
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Start == result[j].Start {
+			return result[i].Depth < result[j].Depth
+		}
+		return result[i].Start < result[j].Start
+	})
+
+	return result
+}
+
+func (p *Profiler) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"frame", "name", "depth", "start_us", "duration_us"})
+	for _, sample := range p.sorted() {
+		writer.Write([]string{
+			strconv.Itoa(sample.Frame),
+			sample.Name,
+			strconv.Itoa(sample.Depth),
+			strconv.FormatInt(sample.Start.Microseconds(), 10),
+			strconv.FormatInt(sample.Duration.Microseconds(), 10),
+		})
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+type chromeTraceEvent struct {
+	Name     string            `json:"name"`
+	Phase    string            `json:"ph"`
+	Time     float64           `json:"ts"`
+	Duration float64           `json:"dur"`
+	Pid      int               `json:"pid"`
+	Tid      int               `json:"tid"`
+	Args     map[string]string `json:"args,omitempty"`
+}
+
+// WriteChromeTrace writes the trace event format which can be opened in
+// chrome://tracing or https://ui.perfetto.dev
+func (p *Profiler) WriteChromeTrace(w io.Writer) error {
+	trace := struct {
+		TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+		DisplayTimeUnit string             `json:"displayTimeUnit"`
+	}{
+		TraceEvents:     []chromeTraceEvent{},
+		DisplayTimeUnit: "ms",
+	}
+
+	for _, sample := range p.sorted() {
+		trace.TraceEvents = append(trace.TraceEvents, chromeTraceEvent{
+			Name:     sample.Name,
+			Phase:    "X",
+			Time:     float64(sample.Start) / float64(time.Microsecond),
+			Duration: float64(sample.Duration) / float64(time.Microsecond),
+			Pid:      1,
+			Tid:      1,
+			Args:     map[string]string{"frame": strconv.Itoa(sample.Frame)},
+		})
+	}
+
+	return json.NewEncoder(w).Encode(trace)
+}
+
+// Save writes a csv or chrome trace depending on if the path ends in .csv or
+// .json
+func (p *Profiler) Save(path string) error {
+	var write func(io.Writer) error
+	switch filepath.Ext(path) {
+	case ".csv":
+		write = p.WriteCSV
+	case ".json":
+		write = p.WriteChromeTrace
+	default:
+		return fmt.Errorf("profile %s must end in .csv or .json", path)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return write(f)
+}
+
+func profileColor(name string) color.RGBA {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	sum := hash.Sum32()
+
+	return color.RGBA{R: uint8(sum) | 0x40, G: uint8(sum>>8) | 0x40, B: uint8(sum>>16) | 0x40, A: 255}
+}
+
+// Draw shows a rolling graph of the last frames, each bar is the systems and
+// scene starts stacked on top of the whole frame in grey
+func (p *Profiler) Draw(screen *ebiten.Image) {
+	if !p.Enabled {
+		return
+	}
+
+	if p.font == nil {
+		p.font = createFontFace(30, 72)
+	}
+
+	const (
+		width  = profilerGraphFrames * profilerBarWidth
+		height = 500
+	)
+	left := float64(20)
+	bottom := float64(windowHeight - 20)
+	ebitenutil.DrawRect(screen, left, bottom-height, width, height, color.RGBA{A: 200})
+
+	// One frame at 60 FPS
+	budget := float64(time.Second/60) / float64(time.Millisecond) * profilerGraphScale
+	ebitenutil.DrawLine(screen, left, bottom-budget, left+width, bottom-budget, color.RGBA{R: 255, A: 255})
+
+	firstFrame := p.frame - profilerGraphFrames + 1
+	heights := make([]float64, profilerGraphFrames)
+	totals := make(map[string]time.Duration)
+	barHeight := func(sample ProfileSample) float64 {
+		return float64(sample.Duration) / float64(time.Millisecond) * profilerGraphScale
+	}
+
+	// Whole frames in grey first so anything not measured still shows up
+	for _, sample := range p.samples {
+		if sample.Frame < firstFrame || sample.Depth != 0 {
+			continue
+		}
+
+		bar := sample.Frame - firstFrame
+		h := barHeight(sample)
+		x := left + float64(bar*profilerBarWidth)
+		ebitenutil.DrawRect(screen, x, bottom-heights[bar]-h, profilerBarWidth, h, color.RGBA{R: 80, G: 80, B: 80, A: 255})
+		heights[bar] += h
+	}
+
+	heights = make([]float64, profilerGraphFrames)
+	for _, sample := range p.samples {
+		if sample.Frame < firstFrame || sample.Depth != 1 {
+			continue
+		}
+
+		totals[sample.Name] += sample.Duration
+
+		bar := sample.Frame - firstFrame
+		h := barHeight(sample)
+		x := left + float64(bar*profilerBarWidth)
+		ebitenutil.DrawRect(screen, x, bottom-heights[bar]-h, profilerBarWidth, h, profileColor(sample.Name))
+		heights[bar] += h
+	}
+
+	var names []string
+	for name := range totals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if totals[names[i]] == totals[names[j]] {
+			return names[i] < names[j]
+		}
+		return totals[names[i]] > totals[names[j]]
+	})
+	if len(names) > profilerGraphTop {
+		names = names[:profilerGraphTop]
+	}
+
+	frames := p.frame - firstFrame + 1
+	if p.frame+1 < frames {
+		frames = p.frame + 1
+	}
+	for i, name := range names {
+		avg := float64(totals[name]) / float64(frames) / float64(time.Millisecond)
+		line := fmt.Sprintf("%s %.2fms", name, avg)
+		text.Draw(screen, line, p.font, int(left)+width+20, int(bottom)-height+(i+1)*40, profileColor(name))
+	}
+}
+
+func typeName(value interface{}) string {
+	t := reflect.TypeOf(value)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// updateWorld is World.Update with each system timed
+func updateWorld(w *ecs.World, dt float32) {
+	for _, system := range w.Systems() {
+		profiler.Measure(systemName(system)+".Update", func() {
+			system.Update(dt)
+		})
+	}
+}
+
+// renderWorld collects the render commands from every RenderingSystem in the
+// order they should be drawn
+func renderWorld(w *ecs.World) RenderCmds {
+	queue := RenderCmds{}
+	for _, system := range w.Systems() {
+		if render, ok := system.(RenderingSystem); ok {
+			profiler.Measure(systemName(system)+".Render", func() {
+				render.Render(&queue)
+			})
+		}
+	}
+
+	profiler.Measure("RenderCmds.Sort", queue.Sort)
+
+	return queue
+}
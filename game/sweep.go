@@ -0,0 +1,104 @@
+package game
+
+import (
+	gomath "math"
+
+	"github.com/SolarLune/resolv"
+	"github.com/sardap/walk-good-maybe-hd/math"
+)
+
+// sweepRect is when a moving by delta starts overlapping b as a fraction of
+// delta, negative if it was already overlapping. Touching isn't overlapping
+// same as resolv. The normal is the side of b it came in through
+func sweepRect(a *resolv.Rectangle, delta math.Vector2, b *resolv.Rectangle) (float64, math.Vector2, bool) {
+	entryX, exitX, ok := sweepAxis(a.X, a.W, delta.X, b.X, b.W)
+	if !ok {
+		return 0, math.Vector2{}, false
+	}
+
+	entryY, exitY, ok := sweepAxis(a.Y, a.H, delta.Y, b.Y, b.H)
+	if !ok {
+		return 0, math.Vector2{}, false
+	}
+
+	entry := gomath.Max(entryX, entryY)
+	exit := gomath.Min(exitX, exitY)
+	if entry >= exit || entry >= 1 || exit <= 0 {
+		return 0, math.Vector2{}, false
+	}
+
+	var normal math.Vector2
+	if entryX > entryY {
+		normal.X = -sign(delta.X)
+	} else {
+		normal.Y = -sign(delta.Y)
+	}
+
+	return entry, normal, true
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+
+	return 0
+}
+
+// contact is the side of b that a is touching as a normal pointing out of b
+// and how far a is into it, it's whichever side a is least far into
+func contact(a, b *resolv.Rectangle) (math.Vector2, float64) {
+	overlapX := gomath.Min(a.X+a.W, b.X+b.W) - gomath.Max(a.X, b.X)
+	overlapY := gomath.Min(a.Y+a.H, b.Y+b.H) - gomath.Max(a.Y, b.Y)
+
+	if overlapX < overlapY {
+		if a.X+a.W/2 < b.X+b.W/2 {
+			return math.Vector2{X: -1}, gomath.Max(overlapX, 0)
+		}
+		return math.Vector2{X: 1}, gomath.Max(overlapX, 0)
+	}
+
+	if a.Y+a.H/2 < b.Y+b.H/2 {
+		return math.Vector2{Y: -1}, gomath.Max(overlapY, 0)
+	}
+	return math.Vector2{Y: 1}, gomath.Max(overlapY, 0)
+}
+
+// depthAlong is how far a is into b on normal's axis
+func depthAlong(a, b *resolv.Rectangle, normal math.Vector2) float64 {
+	if normal.X != 0 {
+		return gomath.Max(gomath.Min(a.X+a.W, b.X+b.W)-gomath.Max(a.X, b.X), 0)
+	}
+
+	return gomath.Max(gomath.Min(a.Y+a.H, b.Y+b.H)-gomath.Max(a.Y, b.Y), 0)
+}
+
+// sweepAxis is when the moving line overlaps the still one
+func sweepAxis(pos, size, delta, otherPos, otherSize float64) (entry, exit float64, ok bool) {
+	if delta == 0 {
+		if pos < otherPos+otherSize && pos+size > otherPos {
+			return gomath.Inf(-1), gomath.Inf(1), true
+		}
+		return 0, 0, false
+	}
+
+	entry = (otherPos - (pos + size)) / delta
+	exit = (otherPos + otherSize - pos) / delta
+	if delta < 0 {
+		entry, exit = exit, entry
+	}
+
+	return entry, exit, true
+}
+
+// overlapArea is how much of a and b overlap, used to pick which parts of two
+// hitboxes decide how they touched
+func overlapArea(a, b *resolv.Rectangle) float64 {
+	overlapX := gomath.Min(a.X+a.W, b.X+b.W) - gomath.Max(a.X, b.X)
+	overlapY := gomath.Min(a.Y+a.H, b.Y+b.H) - gomath.Max(a.Y, b.Y)
+
+	return gomath.Max(overlapX, 0) * gomath.Max(overlapY, 0)
+}
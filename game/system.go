@@ -1,48 +1,315 @@
-package game
-
-import (
-	"sort"
-
-	"github.com/hajimehoshi/ebiten/v2"
-)
-
-type systemPriority int
-
-const (
-	systemPriorityImageRenderSystem systemPriority = iota
-	systemPriorityKaraokeRenderSystem
-	systemPriorityTileImageRenderSystem
-	systemPriorityTextRenderSystem
-	systemPriorityMainGameUiSystem
-	systemPrioritySoundSystem
-	systemPriorityDamageSystem
-	systemPriorityDestoryBoundSystem
-	systemPriorityAnimeSystem
-	systemPriorityResolvSystem
-	systemPriorityVelocitySystem
-	systemPriorityDumbVelocitySystem
-	systemPriorityConstantSpeedSystem
-	systemPriorityScrollingSystem
-	systemPriorityGameRuleSystem
-	systemPriorityPlayerSystem
-	systemPriorityEnemyBiscuitSystem
-	systemPriorityCollisionSystem
-	systemPriorityInputSystem
-)
-
-type RenderCmd interface {
-	Draw(*ebiten.Image)
-	GetLayer() int
-}
-
-type RenderCmds []RenderCmd
-
-func (r RenderCmds) Sort() {
-	sort.Slice(r, func(i, j int) bool {
-		return r[i].GetLayer() < r[j].GetLayer()
-	})
-}
-
-type RenderingSystem interface {
-	Render(*RenderCmds)
-}
+package game
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/EngoEngine/ecs"
+	"github.com/SolarLune/resolv"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// systemRunsAfter is for systems which need other systems to have updated
+// before them, ones which aren't in the world are skipped
+type systemRunsAfter interface {
+	runsAfter() []ecs.System
+}
+
+// systemRunsBefore is the same as systemRunsAfter but the other way around
+type systemRunsBefore interface {
+	runsBefore() []ecs.System
+}
+
+// systemRunsLast puts a system after every other one
+type systemRunsLast interface {
+	runsLast()
+}
+
+// systemEnder is for systems holding on to something outside the world which
+// has to be let go of when the scene ends
+type systemEnder interface {
+	end()
+}
+
+// endSystems tells every system in w which cares that its scene is over
+func endSystems(w *ecs.World) {
+	for _, system := range w.Systems() {
+		if ender, ok := system.(systemEnder); ok {
+			ender.end()
+		}
+	}
+}
+
+// systemOrder is embedded in every system so ecs can be told where it goes
+type systemOrder struct {
+	priority int
+}
+
+func (s *systemOrder) Priority() int {
+	return s.priority
+}
+
+func (s *systemOrder) setPriority(priority int) {
+	s.priority = priority
+}
+
+type systemNode struct {
+	name   string
+	after  []string
+	before []string
+	last   bool
+}
+
+func systemName(system ecs.System) string {
+	return typeName(system)
+}
+
+func systemNames(systems []ecs.System) []string {
+	result := make([]string, len(systems))
+	for i, system := range systems {
+		result[i] = systemName(system)
+	}
+
+	return result
+}
+
+func systemNodes(systems []ecs.System) []systemNode {
+	result := make([]systemNode, len(systems))
+	for i, system := range systems {
+		result[i].name = systemName(system)
+		if after, ok := system.(systemRunsAfter); ok {
+			result[i].after = systemNames(after.runsAfter())
+		}
+		if before, ok := system.(systemRunsBefore); ok {
+			result[i].before = systemNames(before.runsBefore())
+		}
+		_, result[i].last = system.(systemRunsLast)
+	}
+
+	return result
+}
+
+// resolveSystemOrder topologically sorts the systems, ties go to whichever
+// comes first in nodes so the order is the same every time
+func resolveSystemOrder(nodes []systemNode) ([]string, error) {
+	index := make(map[string]int)
+	for i, node := range nodes {
+		if _, ok := index[node.name]; ok {
+			return nil, fmt.Errorf("system %s is added twice", node.name)
+		}
+		index[node.name] = i
+	}
+
+	// edges[i] are the systems which have to wait for i
+	edges := make([][]int, len(nodes))
+	waitingOn := make([]int, len(nodes))
+	addEdge := func(from, to int) {
+		for _, existing := range edges[from] {
+			if existing == to {
+				return
+			}
+		}
+		edges[from] = append(edges[from], to)
+		waitingOn[to]++
+	}
+
+	for i, node := range nodes {
+		if node.last {
+			for j, other := range nodes {
+				if j != i && !other.last {
+					addEdge(j, i)
+				}
+			}
+		}
+
+		for _, name := range node.after {
+			if j, ok := index[name]; ok {
+				addEdge(j, i)
+			}
+		}
+
+		for _, name := range node.before {
+			if j, ok := index[name]; ok {
+				addEdge(i, j)
+			}
+		}
+	}
+
+	done := make([]bool, len(nodes))
+	result := make([]string, 0, len(nodes))
+	for len(result) < len(nodes) {
+		next := -1
+		for i := range nodes {
+			if !done[i] && waitingOn[i] == 0 {
+				next = i
+				break
+			}
+		}
+
+		if next == -1 {
+			return nil, fmt.Errorf("systems can't be ordered: %s", systemCycle(nodes, edges, done))
+		}
+
+		done[next] = true
+		result = append(result, nodes[next].name)
+		for _, to := range edges[next] {
+			waitingOn[to]--
+		}
+	}
+
+	return result, nil
+}
+
+// systemCycle finds a loop in what's left over e.g. "A -> B -> A"
+func systemCycle(nodes []systemNode, edges [][]int, done []bool) string {
+	const (
+		unvisited = iota
+		onPath
+		finished
+	)
+	state := make([]int, len(nodes))
+	var path []int
+
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		switch state[i] {
+		case onPath:
+			for start, j := range path {
+				if j == i {
+					return append(path[start:], i)
+				}
+			}
+		case finished:
+			return nil
+		}
+
+		state[i] = onPath
+		path = append(path, i)
+		for _, to := range edges[i] {
+			if done[to] {
+				continue
+			}
+			if cycle := visit(to); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = finished
+
+		return nil
+	}
+
+	for i := range nodes {
+		if done[i] {
+			continue
+		}
+		if cycle := visit(i); cycle != nil {
+			names := make([]string, len(cycle))
+			for k, j := range cycle {
+				names[k] = nodes[j].name
+			}
+			return strings.Join(names, " -> ")
+		}
+	}
+
+	return "unknown cycle"
+}
+
+// orderSystems sorts w's systems by what they run after and before, ties stay
+// in the order they were already in
+func orderSystems(w *ecs.World) error {
+	systems := w.Systems()
+	order, err := resolveSystemOrder(systemNodes(systems))
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]ecs.System)
+	for _, system := range systems {
+		byName[systemName(system)] = system
+	}
+
+	for i, name := range order {
+		ordered, ok := byName[name].(interface{ setPriority(int) })
+		if !ok {
+			return fmt.Errorf("system %s needs to embed systemOrder", name)
+		}
+		// Never 0 so anything added after this starts off last
+		ordered.setPriority(len(order) - i)
+	}
+	w.SortSystems()
+
+	return nil
+}
+
+// AddSystem is World.AddSystem which then sorts the world's systems by what
+// they run after and before, ties go in the order they were added. It panics
+// if they can't be sorted since that's a mistake in the code
+func AddSystem(w *ecs.World, system ecs.System) {
+	w.AddSystem(system)
+	if err := orderSystems(w); err != nil {
+		panic(err)
+	}
+}
+
+// AddSystemInterface is World.AddSystemInterface sorting the same way as AddSystem
+func AddSystemInterface(w *ecs.World, system ecs.SystemAddByInterfacer, in interface{}, ex interface{}) {
+	w.AddSystemInterface(system, in, ex)
+	if err := orderSystems(w); err != nil {
+		panic(err)
+	}
+}
+
+// dumpSystemOrder writes the order world's systems update in along with why
+func dumpSystemOrder(w io.Writer, world *ecs.World) error {
+	for i, node := range systemNodes(world.Systems()) {
+		line := fmt.Sprintf("%2d %s", i+1, node.name)
+		if node.last {
+			line += " last"
+		}
+		if len(node.after) > 0 {
+			line += " after " + strings.Join(node.after, ", ")
+		}
+		if len(node.before) > 0 {
+			line += " before " + strings.Join(node.before, ", ")
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DumpSystemOrder writes the order the main game's systems update in along
+// with why
+func DumpSystemOrder(w io.Writer) error {
+	m := &MainGameScene{
+		World:  &ecs.World{},
+		Events: CreateEventBus(),
+		Space:  resolv.NewSpace(),
+	}
+	m.addSystems(&Game{})
+
+	return dumpSystemOrder(w, m.World)
+}
+
+type RenderCmd interface {
+	Draw(*ebiten.Image)
+	GetLayer() int
+}
+
+type RenderCmds []RenderCmd
+
+func (r RenderCmds) Sort() {
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].GetLayer() < r[j].GetLayer()
+	})
+}
+
+type RenderingSystem interface {
+	Render(*RenderCmds)
+}
@@ -0,0 +1,182 @@
+package game
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type TransitionType int
+
+const (
+	TransitionNone TransitionType = iota
+	// TransitionFade fades the old scene out to a colour then the new one in
+	TransitionFade
+	// TransitionWipe slides the new scene in from the left over the old one
+	TransitionWipe
+	// TransitionIris opens a circle from the middle showing the new scene
+	TransitionIris
+)
+
+type Transition struct {
+	Type     TransitionType
+	Duration time.Duration
+	// Color is what fade goes through, black if not set
+	Color color.Color
+}
+
+var defaultTransition = Transition{
+	Type:     TransitionFade,
+	Duration: 500 * time.Millisecond,
+	Color:    color.Black,
+}
+
+// Scenes which want a specific transition when they are changed to
+type transitionScene interface {
+	Transition() Transition
+}
+
+const irisMaskSize = 512
+
+var irisMask *ebiten.Image
+
+// getIrisMask returns a white circle which gets scaled up for the iris
+func getIrisMask() *ebiten.Image {
+	if irisMask != nil {
+		return irisMask
+	}
+
+	img := image.NewAlpha(image.Rect(0, 0, irisMaskSize, irisMaskSize))
+	center := float64(irisMaskSize) / 2
+	for y := 0; y < irisMaskSize; y++ {
+		for x := 0; x < irisMaskSize; x++ {
+			dist := math.Hypot(float64(x)+0.5-center, float64(y)+0.5-center)
+			// One pixel of fall off so the edge isn't jagged once scaled
+			a := math.Max(0, math.Min(1, center-dist))
+			img.SetAlpha(x, y, color.Alpha{A: uint8(a * 255)})
+		}
+	}
+
+	irisMask = ebiten.NewImageFromImage(img)
+	return irisMask
+}
+
+type transitionState struct {
+	Transition
+	elapsed time.Duration
+	// from is a snapshot of the old scenes when the change happened
+	from *ebiten.Image
+	to   *ebiten.Image
+	mask *ebiten.Image
+}
+
+func (t Transition) color() color.Color {
+	if t.Color == nil {
+		return color.Black
+	}
+
+	return t.Color
+}
+
+func (t *transitionState) Progress() float64 {
+	if t.Duration <= 0 {
+		return 1
+	}
+
+	return math.Min(1, float64(t.elapsed)/float64(t.Duration))
+}
+
+func (t *transitionState) Done() bool {
+	return t.elapsed >= t.Duration
+}
+
+func (t *transitionState) dispose() {
+	for _, img := range []*ebiten.Image{t.from, t.to, t.mask} {
+		if img != nil {
+			img.Dispose()
+		}
+	}
+	t.from, t.to, t.mask = nil, nil, nil
+}
+
+// Draw renders the new scenes using drawScenes and blends them with the old
+// snapshot
+func (t *transitionState) Draw(screen *ebiten.Image, drawScenes func(*ebiten.Image)) {
+	if t.to == nil {
+		t.to = ebiten.NewImage(windowWidth, windowHeight)
+	}
+	t.to.Clear()
+	drawScenes(t.to)
+
+	progress := t.Progress()
+
+	switch t.Type {
+	case TransitionFade:
+		op := &ebiten.DrawImageOptions{}
+		alpha := progress * 2
+		if progress < 0.5 {
+			screen.DrawImage(t.from, op)
+		} else {
+			screen.DrawImage(t.to, op)
+			alpha = (1 - progress) * 2
+		}
+
+		r, g, b, _ := t.color().RGBA()
+		op = &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(windowWidth, windowHeight)
+		op.ColorM.Scale(float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff, alpha)
+		screen.DrawImage(whitePixelImage(), op)
+
+	case TransitionWipe:
+		edge := int(progress * windowWidth)
+		if edge > 0 {
+			screen.DrawImage(t.to.SubImage(image.Rect(0, 0, edge, windowHeight)).(*ebiten.Image), nil)
+		}
+
+		if edge < windowWidth {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(edge), 0)
+			screen.DrawImage(t.from.SubImage(image.Rect(edge, 0, windowWidth, windowHeight)).(*ebiten.Image), op)
+		}
+
+	case TransitionIris:
+		if t.mask == nil {
+			t.mask = ebiten.NewImage(windowWidth, windowHeight)
+		}
+		t.mask.Clear()
+
+		radius := progress * math.Hypot(windowWidth/2, windowHeight/2)
+		scale := radius * 2 / irisMaskSize
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(-irisMaskSize/2, -irisMaskSize/2)
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(windowWidth/2, windowHeight/2)
+		op.Filter = ebiten.FilterLinear
+		t.mask.DrawImage(getIrisMask(), op)
+
+		op = &ebiten.DrawImageOptions{}
+		op.CompositeMode = ebiten.CompositeModeSourceIn
+		t.mask.DrawImage(t.to, op)
+
+		screen.DrawImage(t.from, nil)
+		screen.DrawImage(t.mask, nil)
+
+	default:
+		screen.DrawImage(t.to, nil)
+	}
+}
+
+var whitePixel *ebiten.Image
+
+// whitePixelImage is a single white pixel for filling areas with colour matrices
+func whitePixelImage() *ebiten.Image {
+	if whitePixel == nil {
+		whitePixel = ebiten.NewImage(1, 1)
+		whitePixel.Fill(color.White)
+	}
+
+	return whitePixel
+}
@@ -3,14 +3,16 @@ module github.com/sardap/walk-good-maybe-hd
 go 1.16
 
 require (
+	github.com/BurntSushi/toml v0.4.1
 	github.com/EngoEngine/ecs v1.0.5
 	github.com/SolarLune/resolv v0.0.0-20210908043747-fb656c998e64
 	github.com/hajimehoshi/ebiten v1.12.12
 	github.com/hajimehoshi/ebiten/v2 v2.1.6
-	github.com/hajimehoshi/go-mp3 v0.3.2 // indirect
+	github.com/hajimehoshi/go-mp3 v0.3.2
 	github.com/hajimehoshi/oto v1.0.1 // indirect
 	github.com/icza/gox v0.0.0-20210726201659-cd40a3f8d324 // indirect
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
+	github.com/oov/psd v0.0.0-20210618170533-9fb823ddb631
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/exp v0.0.0-20210901193431-a062eea981d2 // indirect
 	golang.org/x/image v0.0.0-20210628002857-a66eb6448b8d
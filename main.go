@@ -1,23 +1,125 @@
-package main
-
-import (
-	_ "image/png"
-	"log"
-	"math/rand"
-	"time"
-
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/sardap/walk-good-maybe-hd/game"
-)
-
-func main() {
-	rand.Seed(time.Now().UnixNano())
-
-	game := game.CreateGame()
-
-	ebiten.SetWindowSize(1280, 720)
-	ebiten.SetWindowTitle("Walk Good Maybe HD")
-	if err := ebiten.RunGame(game); err != nil {
-		log.Fatal(err)
-	}
-}
+package main
+
+import (
+	"flag"
+	_ "image/png"
+	"log"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sardap/walk-good-maybe-hd/assets"
+	"github.com/sardap/walk-good-maybe-hd/game"
+	"github.com/sardap/walk-good-maybe-hd/replay"
+	"github.com/sardap/walk-good-maybe-hd/scores"
+	"github.com/sardap/walk-good-maybe-hd/settings"
+)
+
+// loadSettings reads the saved settings falling back to the defaults, window
+// flags win over what was saved
+func loadSettings(opts *options) (*settings.Settings, string) {
+	result := settings.Default()
+
+	path, err := settings.Path()
+	if err != nil {
+		log.Printf("settings won't be saved: %v", err)
+		path = ""
+	} else if loaded, err := settings.LoadFile(path); err == nil {
+		result = loaded
+	} else if !os.IsNotExist(err) {
+		log.Printf("unable to load settings using defaults: %v", err)
+	}
+
+	if opts.fullscreen {
+		result.Fullscreen = true
+	}
+
+	if opts.scale > 0 {
+		result.Fullscreen = false
+		result.WindowScale = opts.scale
+	}
+
+	return result, path
+}
+
+// scoreStorage is the high score file or nothing if there's nowhere for it
+func scoreStorage() scores.Storage {
+	path, err := scores.Path()
+	if err != nil {
+		log.Printf("high scores won't be saved: %v", err)
+		return &scores.MemoryStorage{}
+	}
+
+	return &scores.FileStorage{Path: path}
+}
+
+func main() {
+	rand.Seed(time.Now().UnixNano())
+
+	opts, err := parseOptions(os.Args[1:], os.Stderr)
+	if err == flag.ErrHelp {
+		return
+	} else if err != nil {
+		log.Fatal(err)
+	}
+
+	if opts.systems {
+		if err := game.DumpSystemOrder(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	g := game.CreateGame()
+	g.SetSettings(loadSettings(opts))
+	g.SetScores(scoreStorage())
+	g.SetMuted(opts.mute)
+	g.SetTickRate(opts.tickRate)
+	if opts.profilePath != "" {
+		g.Profiler().SetEnabled(true)
+	}
+
+	switch opts.scene {
+	case sceneMain:
+		scene := &game.MainGameScene{Seed: opts.seed, RecordPath: opts.recordPath}
+		if opts.replayPath != "" {
+			scene.Replay, err = replay.LoadFile(opts.replayPath)
+			if err != nil {
+				log.Fatalf("unable to load replay: %v", err)
+			}
+			if scene.Replay.TickRate > game.MaxTickRate {
+				log.Fatalf("replay tick rate %d is over %d", scene.Replay.TickRate, game.MaxTickRate)
+			}
+			// It only plays back the same at the rate it was recorded at
+			g.SetTickRate(scene.Replay.TickRate)
+		}
+		g.SetStartScene(scene)
+	case sceneKaraoke:
+		var session *game.KaraokeSession
+		if opts.karaokePath != "" {
+			session, err = game.LoadKaraokeChart(opts.karaokePath)
+		} else {
+			session, err = game.LoadKaraokeSession(assets.LoadKaraoke(assets.KaraokeTest))
+		}
+		if err != nil {
+			log.Fatalf("unable to load karaoke chart: %v", err)
+		}
+		g.SetStartScene(&game.KaraokeScene{Session: session})
+	}
+
+	ebiten.SetWindowTitle("Walk Good Maybe HD")
+	err = ebiten.RunGame(g)
+	// Anything still running gets to save e.g. a recording
+	g.Close()
+
+	if opts.profilePath != "" {
+		if err := g.Profiler().Save(opts.profilePath); err != nil {
+			log.Printf("unable to save profile: %v", err)
+		}
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
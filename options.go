@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/sardap/walk-good-maybe-hd/game"
+	"github.com/sardap/walk-good-maybe-hd/settings"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+)
+
+const (
+	sceneTitle   = "title"
+	sceneMain    = "main"
+	sceneKaraoke = "karaoke"
+)
+
+type options struct {
+	scene       string
+	seedCode    string
+	seed        int64
+	karaokePath string
+	mute        bool
+	fullscreen  bool
+	scale       int
+	tickRate    int
+	// Print the order systems update in and exit
+	systems bool
+	// Where to write the profile when the game closes
+	profilePath string
+	// Where to save the player's input for the run
+	recordPath string
+	// Recorded run to play back
+	replayPath string
+}
+
+func parseOptions(args []string, output io.Writer) (*options, error) {
+	result := &options{}
+
+	flags := flag.NewFlagSet("walk-good-maybe-hd", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.StringVar(&result.scene, "scene", sceneTitle, "scene to start on: title, main or karaoke")
+	flags.StringVar(&result.seedCode, "seed", "", "seed code for the city when starting on main e.g. 0000-0001")
+	flags.StringVar(&result.karaokePath, "karaoke", "", "karaoke chart json to play when starting on karaoke")
+	flags.BoolVar(&result.mute, "mute", false, "turn off all sound")
+	flags.BoolVar(&result.fullscreen, "fullscreen", false, "start fullscreen")
+	flags.IntVar(&result.scale, "scale", 0, fmt.Sprintf("window size as a multiple of %dx%d", game.NativeWidth, game.NativeHeight))
+	flags.IntVar(&result.tickRate, "tps", game.DefaultTickRate, "game updates per second")
+	flags.BoolVar(&result.systems, "systems", false, "print the order the main game's systems update in and exit")
+	flags.StringVar(&result.profilePath, "profile", "", "profile systems and write the timings to a .csv or chrome trace .json on exit")
+	flags.StringVar(&result.recordPath, "record", "", "save the player's input to this file when the run ends, only with -scene main")
+	flags.StringVar(&result.replayPath, "replay", "", "play back a run saved with -record, only with -scene main")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	switch result.scene {
+	case sceneTitle, sceneMain, sceneKaraoke:
+	default:
+		return nil, fmt.Errorf("unknown -scene %q must be one of title, main or karaoke", result.scene)
+	}
+
+	if result.seedCode != "" {
+		if result.scene != sceneMain {
+			return nil, errors.New("-seed only works with -scene main")
+		}
+
+		seed, err := utility.DecodeSeed(result.seedCode)
+		if err != nil {
+			return nil, fmt.Errorf("-seed %q: %w", result.seedCode, err)
+		}
+		result.seed = seed
+	}
+
+	if (result.recordPath != "" || result.replayPath != "") && result.scene != sceneMain {
+		return nil, errors.New("-record and -replay only work with -scene main")
+	}
+
+	if result.recordPath != "" && result.replayPath != "" {
+		return nil, errors.New("-record and -replay can't be used together")
+	}
+
+	// The seed comes from the replay
+	if result.replayPath != "" && result.seedCode != "" {
+		return nil, errors.New("-seed and -replay can't be used together")
+	}
+
+	if result.karaokePath != "" && result.scene != sceneKaraoke {
+		return nil, errors.New("-karaoke only works with -scene karaoke")
+	}
+
+	if result.scale < 0 || result.scale > settings.MaxScale {
+		return nil, fmt.Errorf("-scale must be between 1 and %d got %d", settings.MaxScale, result.scale)
+	}
+
+	if result.scale > 0 && result.fullscreen {
+		return nil, errors.New("-scale and -fullscreen can't be used together")
+	}
+
+	if result.tickRate < 1 || result.tickRate > game.MaxTickRate {
+		return nil, fmt.Errorf("-tps must be between 1 and %d got %d", game.MaxTickRate, result.tickRate)
+	}
+
+	if result.profilePath != "" {
+		switch filepath.Ext(result.profilePath) {
+		case ".csv", ".json":
+		default:
+			return nil, fmt.Errorf("-profile %q must end in .csv or .json", result.profilePath)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sardap/walk-good-maybe-hd/game"
+	"github.com/sardap/walk-good-maybe-hd/utility"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOptions(t *testing.T) {
+	t.Parallel()
+
+	opts, err := parseOptions(nil, ioutil.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, sceneTitle, opts.scene)
+	assert.Equal(t, game.DefaultTickRate, opts.tickRate)
+	assert.False(t, opts.mute)
+
+	opts, err = parseOptions([]string{"-scene", "main", "-seed", "0000-0001", "-mute", "-tps", "120", "-scale", "4"}, ioutil.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, sceneMain, opts.scene)
+	assert.Equal(t, int64(1), opts.seed)
+	assert.True(t, opts.mute)
+	assert.Equal(t, 120, opts.tickRate)
+	assert.Equal(t, 4, opts.scale)
+
+	opts, err = parseOptions([]string{"-scene", "karaoke", "-karaoke", "chart.json", "-fullscreen"}, ioutil.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, "chart.json", opts.karaokePath)
+	assert.True(t, opts.fullscreen)
+
+	opts, err = parseOptions([]string{"-systems", "-profile", "run.json"}, ioutil.Discard)
+	assert.NoError(t, err)
+	assert.True(t, opts.systems)
+	assert.Equal(t, "run.json", opts.profilePath)
+
+	opts, err = parseOptions([]string{"-scene", "main", "-seed", "0000-0001", "-record", "bug.wgmr"}, ioutil.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, "bug.wgmr", opts.recordPath)
+
+	opts, err = parseOptions([]string{"-scene", "main", "-replay", "bug.wgmr"}, ioutil.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, "bug.wgmr", opts.replayPath)
+
+	_, err = parseOptions([]string{"-h"}, ioutil.Discard)
+	assert.ErrorIs(t, err, flag.ErrHelp)
+
+	_, err = parseOptions([]string{"-scene", "main", "-seed", "!!!!"}, ioutil.Discard)
+	assert.ErrorIs(t, err, utility.ErrInvalidSeedCode)
+
+	_, err = parseOptions([]string{"-scene", "main", "-seed", "0000-0000"}, ioutil.Discard)
+	assert.ErrorIs(t, err, utility.ErrInvalidSeedCode)
+
+	invalid := []struct {
+		args []string
+		msg  string
+	}{
+		{[]string{"-scene", "options"}, "unknown -scene"},
+		{[]string{"-seed", "0000-0001"}, "-seed only works with -scene main"},
+		{[]string{"-scene", "main", "-karaoke", "chart.json"}, "-karaoke only works with -scene karaoke"},
+		{[]string{"-scale", "-1"}, "-scale must be between"},
+		{[]string{"-scale", "9"}, "-scale must be between"},
+		{[]string{"-scale", "2", "-fullscreen"}, "can't be used together"},
+		{[]string{"-tps", "0"}, "-tps must be between"},
+		{[]string{"-tps", "100000"}, "-tps must be between"},
+		{[]string{"main"}, "unexpected argument"},
+		{[]string{"-scale", "big"}, "invalid value"},
+		{[]string{"-profile", "run.txt"}, "must end in .csv or .json"},
+		{[]string{"-record", "bug.wgmr"}, "only work with -scene main"},
+		{[]string{"-scene", "karaoke", "-replay", "bug.wgmr"}, "only work with -scene main"},
+		{[]string{"-scene", "main", "-record", "a.wgmr", "-replay", "b.wgmr"}, "can't be used together"},
+		{[]string{"-scene", "main", "-seed", "0000-0001", "-replay", "bug.wgmr"}, "can't be used together"},
+	}
+
+	for _, test := range invalid {
+		_, err := parseOptions(test.args, ioutil.Discard)
+		if assert.Error(t, err, test.args) {
+			assert.Contains(t, err.Error(), test.msg, test.args)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package replay
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sardap/walk-good-maybe-hd/components"
+)
+
+// Player steps through a replay one tick at a time. Call Advance once the
+// world has been updated for the tick.
+type Player struct {
+	replay *Replay
+	tick   int
+	empty  Frame
+}
+
+func CreatePlayer(replay *Replay) *Player {
+	return &Player{
+		replay: replay,
+		empty: Frame{
+			PressedDuration: make([]int, components.InputKindLength),
+			JustPressed:     make([]bool, components.InputKindLength),
+			JustReleased:    make([]bool, components.InputKindLength),
+		},
+	}
+}
+
+func (p *Player) Frame() *Frame {
+	if p.Done() {
+		return &p.empty
+	}
+
+	return &p.replay.Frames[p.tick]
+}
+
+func (p *Player) Advance() {
+	if !p.Done() {
+		p.tick++
+	}
+}
+
+func (p *Player) Done() bool {
+	return p.tick >= len(p.replay.Frames)
+}
+
+func (p *Player) Tick() int {
+	return p.tick
+}
+
+// Every input kind gets it's own fake key so the recorded values come back
+// exactly even when the real mapping shares a key between kinds
+func (p *Player) KeyboardInputType() components.KeyboardInputType {
+	result := components.KeyboardInputType{
+		Mapping: make(map[components.InputKind]ebiten.Key),
+		Driver:  &KeyboardDriver{player: p},
+	}
+
+	for kind := components.InputKind(0); kind < components.InputKindLength; kind++ {
+		result.Mapping[kind] = ebiten.Key(kind)
+	}
+
+	return result
+}
+
+func (p *Player) GamepadInputType() components.GamepadInputType {
+	result := components.GamepadInputType{
+		Id:        0,
+		MoveAxisX: 0,
+		MoveAxisY: 1,
+		Mapping:   make(map[components.InputKind]ebiten.GamepadButton),
+		Driver:    &GamepadDriver{player: p},
+	}
+
+	for kind := components.InputKind(0); kind < components.InputKindLength; kind++ {
+		// Left and right come from the axis
+		if kind == components.InputKindMoveLeft || kind == components.InputKindMoveRight {
+			continue
+		}
+		result.Mapping[kind] = ebiten.GamepadButton(kind)
+	}
+
+	return result
+}
+
+type KeyboardDriver struct {
+	player *Player
+}
+
+func (d *KeyboardDriver) KeyPressDuration(k ebiten.Key) int {
+	return d.player.Frame().PressedDuration[k]
+}
+
+func (d *KeyboardDriver) IsKeyJustPressed(k ebiten.Key) bool {
+	return d.player.Frame().JustPressed[k]
+}
+
+func (d *KeyboardDriver) IsKeyJustReleased(k ebiten.Key) bool {
+	return d.player.Frame().JustReleased[k]
+}
+
+type GamepadDriver struct {
+	player *Player
+}
+
+func (d *GamepadDriver) GamepadAxis(_ ebiten.GamepadID, axis int) float64 {
+	frame := d.player.Frame()
+
+	if axis != 0 {
+		return 0
+	}
+
+	switch {
+	case frame.PressedDuration[components.InputKindMoveRight] > 0:
+		return 1
+	case frame.PressedDuration[components.InputKindMoveLeft] > 0:
+		return -1
+	}
+
+	return 0
+}
+
+func (d *GamepadDriver) GamepadButtonPressDuration(_ ebiten.GamepadID, btn ebiten.GamepadButton) int {
+	return d.player.Frame().PressedDuration[btn]
+}
+
+func (d *GamepadDriver) IsGamepadButtonJustPressed(_ ebiten.GamepadID, btn ebiten.GamepadButton) bool {
+	return d.player.Frame().JustPressed[btn]
+}
+
+func (d *GamepadDriver) IsGamepadButtonJustReleased(_ ebiten.GamepadID, btn ebiten.GamepadButton) bool {
+	return d.player.Frame().JustReleased[btn]
+}
+
+func (d *GamepadDriver) Ready(*components.GamepadInputType) bool {
+	return false
+}
@@ -0,0 +1,220 @@
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/sardap/walk-good-maybe-hd/components"
+)
+
+const (
+	magic   = "WGMR"
+	version = 2
+)
+
+var (
+	ErrInvalidReplay      = errors.New("not a replay file")
+	ErrUnsupportedVersion = errors.New("unsupported replay version")
+)
+
+// Frame is the movement state of a single tick
+type Frame struct {
+	PressedDuration []int
+	JustPressed     []bool
+	JustReleased    []bool
+	// FastGameSpeed is if the tick was sped up
+	FastGameSpeed bool
+}
+
+type Replay struct {
+	Seed int64
+	// TickRate is the updates per second it was recorded at, it only plays
+	// back the same at that rate
+	TickRate int
+	Frames   []Frame
+}
+
+func (r *Replay) Record(move *components.MovementComponent, fastGameSpeed bool) {
+	frame := Frame{
+		PressedDuration: make([]int, components.InputKindLength),
+		JustPressed:     make([]bool, components.InputKindLength),
+		JustReleased:    make([]bool, components.InputKindLength),
+		FastGameSpeed:   fastGameSpeed,
+	}
+	copy(frame.PressedDuration, move.PressedDuration)
+	copy(frame.JustPressed, move.JustPressed)
+	copy(frame.JustReleased, move.JustReleased)
+
+	r.Frames = append(r.Frames, frame)
+}
+
+func packBools(bools []bool) (result uint64) {
+	for i, b := range bools {
+		if b {
+			result |= 1 << i
+		}
+	}
+
+	return
+}
+
+func unpackBools(packed uint64, length int) []bool {
+	result := make([]bool, length)
+	for i := range result {
+		result[i] = packed&(1<<i) != 0
+	}
+
+	return result
+}
+
+// Save writes the replay gzipped, every number is a varint so idle frames
+// only cost a few bytes before compression
+func Save(w io.Writer, r *Replay) error {
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	putUvarint := func(x uint64) {
+		n := binary.PutUvarint(buf, x)
+		bw.Write(buf[:n])
+	}
+
+	bw.WriteString(magic)
+	putUvarint(version)
+	n := binary.PutVarint(buf, r.Seed)
+	bw.Write(buf[:n])
+	putUvarint(uint64(r.TickRate))
+	putUvarint(uint64(components.InputKindLength))
+	putUvarint(uint64(len(r.Frames)))
+
+	for _, frame := range r.Frames {
+		for kind := components.InputKind(0); kind < components.InputKindLength; kind++ {
+			putUvarint(uint64(frame.PressedDuration[kind]))
+		}
+		putUvarint(packBools(frame.JustPressed))
+		putUvarint(packBools(frame.JustReleased))
+		if frame.FastGameSpeed {
+			putUvarint(1)
+		} else {
+			putUvarint(0)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func Load(r io.Reader) (*Replay, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrInvalidReplay
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, header); err != nil || string(header) != magic {
+		return nil, ErrInvalidReplay
+	}
+
+	if v, err := binary.ReadUvarint(br); err != nil {
+		return nil, err
+	} else if v != version {
+		return nil, fmt.Errorf("%w %d", ErrUnsupportedVersion, v)
+	}
+
+	result := &Replay{}
+	if result.Seed, err = binary.ReadVarint(br); err != nil {
+		return nil, err
+	}
+
+	tickRate, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if tickRate < 1 || tickRate > math.MaxInt32 {
+		return nil, ErrInvalidReplay
+	}
+	result.TickRate = int(tickRate)
+
+	kindCount, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if kindCount > 64 {
+		return nil, ErrInvalidReplay
+	}
+
+	frameCount, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := uint64(0); i < frameCount; i++ {
+		frame := Frame{
+			PressedDuration: make([]int, components.InputKindLength),
+		}
+
+		for kind := 0; kind < int(kindCount); kind++ {
+			duration, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, err
+			}
+			// Kinds added after the replay was recorded are left unpressed
+			if kind < int(components.InputKindLength) {
+				frame.PressedDuration[kind] = int(duration)
+			}
+		}
+
+		justPressed, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		frame.JustPressed = unpackBools(justPressed, int(components.InputKindLength))
+
+		justReleased, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		frame.JustReleased = unpackBools(justReleased, int(components.InputKindLength))
+
+		fastGameSpeed, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		frame.FastGameSpeed = fastGameSpeed != 0
+
+		result.Frames = append(result.Frames, frame)
+	}
+
+	return result, nil
+}
+
+func SaveFile(path string, r *Replay) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return Save(f, r)
+}
+
+func LoadFile(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
@@ -0,0 +1,104 @@
+package replay_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/replay"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestReplay() *replay.Replay {
+	r := &replay.Replay{Seed: -1234, TickRate: 120}
+
+	move := components.CreateMovementComponent()
+	for i := 0; i < 100; i++ {
+		move.PressedDuration[components.InputKindMoveRight] = i
+		move.JustPressed[components.InputKindJump] = i%10 == 0
+		move.JustReleased[components.InputKindShoot] = i%7 == 0
+		if i > 50 {
+			move.PressedDuration[components.InputKindMoveLeft]++
+		}
+		r.Record(move, i%3 == 0)
+	}
+
+	return r
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	r := createTestReplay()
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, replay.Save(buf, r))
+	assert.Less(t, buf.Len(), 500, "replay should be compact")
+
+	loaded, err := replay.Load(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, r, loaded)
+
+	_, err = replay.Load(bytes.NewBufferString("garbage"))
+	assert.ErrorIs(t, err, replay.ErrInvalidReplay)
+
+	buf.Reset()
+	assert.NoError(t, replay.Save(buf, &replay.Replay{}))
+	_, err = replay.Load(buf)
+	assert.ErrorIs(t, err, replay.ErrInvalidReplay, "needs a tick rate")
+}
+
+func TestRecordCopies(t *testing.T) {
+	t.Parallel()
+
+	r := &replay.Replay{}
+	move := components.CreateMovementComponent()
+	move.PressedDuration[components.InputKindJump] = 1
+	r.Record(move, false)
+	move.PressedDuration[components.InputKindJump] = 2
+
+	assert.Equal(t, 1, r.Frames[0].PressedDuration[components.InputKindJump])
+}
+
+func TestDrivers(t *testing.T) {
+	t.Parallel()
+
+	r := createTestReplay()
+	player := replay.CreatePlayer(r)
+
+	keyboard := player.KeyboardInputType()
+	gamepad := player.GamepadInputType()
+
+	for i, frame := range r.Frames {
+		assert.False(t, player.Done())
+		assert.Equal(t, i, player.Tick())
+
+		for kind, key := range keyboard.Mapping {
+			assert.Equal(t, frame.PressedDuration[kind], keyboard.Driver.KeyPressDuration(key))
+			assert.Equal(t, frame.JustPressed[kind], keyboard.Driver.IsKeyJustPressed(key))
+			assert.Equal(t, frame.JustReleased[kind], keyboard.Driver.IsKeyJustReleased(key))
+		}
+
+		for kind, btn := range gamepad.Mapping {
+			assert.Equal(t, frame.PressedDuration[kind], gamepad.Driver.GamepadButtonPressDuration(gamepad.Id, btn))
+			assert.Equal(t, frame.JustPressed[kind], gamepad.Driver.IsGamepadButtonJustPressed(gamepad.Id, btn))
+			assert.Equal(t, frame.JustReleased[kind], gamepad.Driver.IsGamepadButtonJustReleased(gamepad.Id, btn))
+		}
+
+		axis := gamepad.Driver.GamepadAxis(gamepad.Id, gamepad.MoveAxisX)
+		switch {
+		case frame.PressedDuration[components.InputKindMoveRight] > 0:
+			assert.Equal(t, float64(1), axis)
+		case frame.PressedDuration[components.InputKindMoveLeft] > 0:
+			assert.Equal(t, float64(-1), axis)
+		default:
+			assert.Zero(t, axis)
+		}
+
+		player.Advance()
+	}
+
+	assert.True(t, player.Done())
+	assert.Zero(t, keyboard.Driver.KeyPressDuration(ebiten.Key(components.InputKindMoveRight)), "nothing pressed once finished")
+}
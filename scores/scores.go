@@ -0,0 +1,203 @@
+package scores
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	version  = 1
+	dirName  = "walk-good-maybe-hd"
+	fileName = "scores.json"
+	// MaxEntries is how many scores the table keeps
+	MaxEntries = 10
+	// NameLength is how many letters go in a name
+	NameLength = 3
+)
+
+var ErrUnsupportedVersion = errors.New("unsupported scores version")
+
+type Entry struct {
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+	// Distance is in metres
+	Distance float64   `json:"distance"`
+	Seed     int64     `json:"seed"`
+	Date     time.Time `json:"date"`
+}
+
+// Table is the best scores highest first
+type Table struct {
+	Entries []Entry
+}
+
+// Rank is where score would go in the table from 0, -1 if it wouldn't make
+// it. Ties go after the scores already there
+func (t *Table) Rank(score int) int {
+	rank := sort.Search(len(t.Entries), func(i int) bool {
+		return t.Entries[i].Score < score
+	})
+
+	if rank >= MaxEntries {
+		return -1
+	}
+
+	return rank
+}
+
+// Qualifies is if score would get into the table
+func (t *Table) Qualifies(score int) bool {
+	return t.Rank(score) >= 0
+}
+
+// Add puts entry in the table dropping whatever falls off the bottom, it
+// returns where it went or -1 if it didn't make it
+func (t *Table) Add(entry Entry) int {
+	rank := t.Rank(entry.Score)
+	if rank < 0 {
+		return -1
+	}
+
+	t.Entries = append(t.Entries, Entry{})
+	copy(t.Entries[rank+1:], t.Entries[rank:])
+	t.Entries[rank] = entry
+
+	if len(t.Entries) > MaxEntries {
+		t.Entries = t.Entries[:MaxEntries]
+	}
+
+	return rank
+}
+
+type file struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+func Save(w io.Writer, t *Table) error {
+	f := file{
+		Version: version,
+		Entries: t.Entries,
+	}
+	if f.Entries == nil {
+		f.Entries = []Entry{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(f)
+}
+
+// Load reads a table putting it back in order in case it was edited by hand
+func Load(r io.Reader) (*Table, error) {
+	f := file{}
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if f.Version != version {
+		return nil, fmt.Errorf("%w %d", ErrUnsupportedVersion, f.Version)
+	}
+
+	result := &Table{}
+	for _, entry := range f.Entries {
+		if len(entry.Name) != NameLength {
+			return nil, fmt.Errorf("name %q must be %d letters", entry.Name, NameLength)
+		}
+
+		result.Entries = append(result.Entries, entry)
+	}
+
+	sort.SliceStable(result.Entries, func(i, j int) bool {
+		return result.Entries[i].Score > result.Entries[j].Score
+	})
+	if len(result.Entries) > MaxEntries {
+		result.Entries = result.Entries[:MaxEntries]
+	}
+
+	return result, nil
+}
+
+// Storage is somewhere the table is kept between runs of the game
+type Storage interface {
+	// Load is an empty table if nothing has been saved yet
+	Load() (*Table, error)
+	Save(*Table) error
+}
+
+// FileStorage keeps the table in a json file
+type FileStorage struct {
+	Path string
+}
+
+func (s *FileStorage) Load() (*Table, error) {
+	f, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return &Table{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+func (s *FileStorage) Save(t *Table) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return err
+	}
+
+	// Write somewhere else first so a crash half way through doesn't eat the
+	// old scores
+	f, err := os.CreateTemp(filepath.Dir(s.Path), fileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := Save(f, t); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.Path)
+}
+
+// MemoryStorage only lasts as long as the game is open, it's for when there's
+// nowhere to write to
+type MemoryStorage struct {
+	table Table
+}
+
+func (s *MemoryStorage) Load() (*Table, error) {
+	result := &Table{
+		Entries: append([]Entry{}, s.table.Entries...),
+	}
+
+	return result, nil
+}
+
+func (s *MemoryStorage) Save(t *Table) error {
+	s.table.Entries = append([]Entry{}, t.Entries...)
+	return nil
+}
+
+// Path is where the scores live in the users config dir
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, dirName, fileName), nil
+}
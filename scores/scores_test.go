@@ -0,0 +1,125 @@
+package scores_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sardap/walk-good-maybe-hd/scores"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+
+	table := &scores.Table{}
+	assert.True(t, table.Qualifies(0), "anything gets into an empty table")
+
+	for i := 0; i < scores.MaxEntries; i++ {
+		assert.Equal(t, 0, table.Add(scores.Entry{Name: "AAA", Score: i * 10}), "higher scores should go first")
+	}
+	assert.Len(t, table.Entries, scores.MaxEntries)
+
+	assert.False(t, table.Qualifies(0), "ties with the bottom don't make it")
+	assert.Equal(t, -1, table.Add(scores.Entry{Name: "BAD", Score: 0}))
+	assert.Equal(t, 9, table.Rank(5))
+
+	assert.Equal(t, 2, table.Add(scores.Entry{Name: "TIE", Score: 80}), "ties go after the ones already there")
+	assert.Len(t, table.Entries, scores.MaxEntries, "the lowest should fall off")
+	assert.Equal(t, "TIE", table.Entries[2].Name)
+	assert.Equal(t, 10, table.Entries[len(table.Entries)-1].Score)
+
+	for i := 1; i < len(table.Entries); i++ {
+		assert.GreaterOrEqual(t, table.Entries[i-1].Score, table.Entries[i].Score)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	table := &scores.Table{}
+	table.Add(scores.Entry{
+		Name:     "WHL",
+		Score:    1234,
+		Distance: 56.5,
+		Seed:     42,
+		Date:     time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+	})
+	table.Add(scores.Entry{Name: "BIS", Score: 10})
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, scores.Save(buf, table))
+	loaded, err := scores.Load(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, table, loaded)
+
+	loaded, err = scores.Load(bytes.NewBufferString(`{"version": 1, "entries": [{"name": "LOW", "score": 1}, {"name": "TOP", "score": 9}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "TOP", loaded.Entries[0].Name, "hand edited files should be put back in order")
+
+	invalid := []string{
+		`{"version": 2}`,
+		`{"version": 1, "entries": [{"name": "TOOLONG", "score": 1}]}`,
+		`not json`,
+	}
+	for _, str := range invalid {
+		_, err := scores.Load(bytes.NewBufferString(str))
+		assert.Errorf(t, err, "%s should fail", str)
+	}
+}
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+
+	table := &scores.Table{}
+	table.Add(scores.Entry{Name: "WHL", Score: 100})
+
+	storages := map[string]scores.Storage{
+		"file":   &scores.FileStorage{Path: filepath.Join(t.TempDir(), "nested", "scores.json")},
+		"memory": &scores.MemoryStorage{},
+	}
+	for name, storage := range storages {
+		empty, err := storage.Load()
+		assert.NoErrorf(t, err, "%s nothing saved yet isn't an error", name)
+		assert.Emptyf(t, empty.Entries, "%s", name)
+
+		assert.NoError(t, storage.Save(table))
+		loaded, err := storage.Load()
+		assert.NoError(t, err)
+		assert.Equalf(t, table.Entries, loaded.Entries, "%s", name)
+
+		loaded.Add(scores.Entry{Name: "NEW", Score: 200})
+		reloaded, _ := storage.Load()
+		assert.Lenf(t, reloaded.Entries, 1, "%s changes shouldn't be kept until saved", name)
+	}
+}
+
+func TestFileStorageSave(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	storage := &scores.FileStorage{Path: filepath.Join(dir, "scores.json")}
+
+	table := &scores.Table{}
+	table.Add(scores.Entry{Name: "WHL", Score: 100})
+	assert.NoError(t, storage.Save(table))
+	table.Add(scores.Entry{Name: "NEW", Score: 200})
+	assert.NoError(t, storage.Save(table))
+
+	loaded, err := storage.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, table.Entries, loaded.Entries)
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1, "temp files should be cleaned up")
+
+	// Can't rename over a dir so the save fails without leaving anything behind
+	blocked := &scores.FileStorage{Path: filepath.Join(dir, "blocked")}
+	assert.NoError(t, os.MkdirAll(filepath.Join(blocked.Path, "inside"), 0755))
+	assert.Error(t, blocked.Save(table))
+	files, _ = os.ReadDir(dir)
+	assert.Len(t, files, 2)
+}
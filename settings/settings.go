@@ -0,0 +1,258 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sardap/walk-good-maybe-hd/components"
+)
+
+const (
+	version  = 1
+	dirName  = "walk-good-maybe-hd"
+	fileName = "settings.json"
+	MaxScale = 8
+)
+
+var ErrUnsupportedVersion = errors.New("unsupported settings version")
+
+type Settings struct {
+	Volume    float64
+	InputMode components.InputMode
+	// Fullscreen wins over WindowScale
+	Fullscreen bool
+	// WindowScale of 0 leaves the window at it's default size
+	WindowScale int
+	Keyboard    map[components.InputKind]ebiten.Key
+	Gamepad     map[components.InputKind]ebiten.GamepadButton
+}
+
+func Default() *Settings {
+	result := &Settings{
+		Volume:    1,
+		InputMode: components.InputModeKeyboard,
+		Keyboard:  make(map[components.InputKind]ebiten.Key),
+		Gamepad:   make(map[components.InputKind]ebiten.GamepadButton),
+	}
+
+	for kind, key := range components.DefaultKeyboardInputType().Mapping {
+		result.Keyboard[kind] = key
+	}
+
+	for kind, btn := range components.DefaultGamepadInputType().Mapping {
+		result.Gamepad[kind] = btn
+	}
+
+	return result
+}
+
+func (s *Settings) Copy() *Settings {
+	result := *s
+	result.Keyboard = make(map[components.InputKind]ebiten.Key)
+	result.Gamepad = make(map[components.InputKind]ebiten.GamepadButton)
+
+	for kind, key := range s.Keyboard {
+		result.Keyboard[kind] = key
+	}
+
+	for kind, btn := range s.Gamepad {
+		result.Gamepad[kind] = btn
+	}
+
+	return &result
+}
+
+// ApplyMapping replaces the bindings in the component with the ones in the
+// settings, anything not in the settings is left alone
+func (s *Settings) ApplyMapping(com *components.InputComponent) {
+	if com.Keyboard.Mapping != nil {
+		mapping := make(map[components.InputKind]ebiten.Key)
+		for kind, key := range com.Keyboard.Mapping {
+			mapping[kind] = key
+		}
+		for kind, key := range s.Keyboard {
+			mapping[kind] = key
+		}
+		com.Keyboard.Mapping = mapping
+	}
+
+	if com.Gamepad.Mapping != nil {
+		mapping := make(map[components.InputKind]ebiten.GamepadButton)
+		for kind, btn := range com.Gamepad.Mapping {
+			mapping[kind] = btn
+		}
+		for kind, btn := range s.Gamepad {
+			mapping[kind] = btn
+		}
+		com.Gamepad.Mapping = mapping
+	}
+}
+
+// file is what actually gets written, names are used instead of numbers so
+// it can be edited by hand and survives new input kinds being added
+type file struct {
+	Version     int               `json:"version"`
+	Volume      *float64          `json:"volume"`
+	InputMode   string            `json:"input_mode"`
+	Fullscreen  bool              `json:"fullscreen"`
+	WindowScale int               `json:"window_scale"`
+	Keyboard    map[string]string `json:"keyboard"`
+	Gamepad     map[string]int    `json:"gamepad"`
+}
+
+func parseInputKind(name string) (components.InputKind, error) {
+	for kind := components.InputKind(0); kind < components.InputKindLength; kind++ {
+		if kind.String() == name {
+			return kind, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown input %q", name)
+}
+
+func parseKey(name string) (ebiten.Key, error) {
+	for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+		if key.String() == name {
+			return key, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown key %q", name)
+}
+
+func parseInputMode(name string) (components.InputMode, error) {
+	for _, mode := range []components.InputMode{components.InputModeGamepad, components.InputModeKeyboard} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown input mode %q", name)
+}
+
+func Save(w io.Writer, s *Settings) error {
+	f := file{
+		Version:     version,
+		Volume:      &s.Volume,
+		InputMode:   s.InputMode.String(),
+		Fullscreen:  s.Fullscreen,
+		WindowScale: s.WindowScale,
+		Keyboard:    make(map[string]string),
+		Gamepad:     make(map[string]int),
+	}
+
+	for kind, key := range s.Keyboard {
+		f.Keyboard[kind.String()] = key.String()
+	}
+
+	for kind, btn := range s.Gamepad {
+		f.Gamepad[kind.String()] = int(btn)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(f)
+}
+
+// Load reads settings over the top of the defaults so missing fields keep
+// their default values
+func Load(r io.Reader) (*Settings, error) {
+	f := file{}
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if f.Version != version {
+		return nil, fmt.Errorf("%w %d", ErrUnsupportedVersion, f.Version)
+	}
+
+	result := Default()
+	if f.Volume != nil {
+		if *f.Volume < 0 || *f.Volume > 1 {
+			return nil, fmt.Errorf("volume must be between 0 and 1 got %v", *f.Volume)
+		}
+		result.Volume = *f.Volume
+	}
+
+	if f.InputMode != "" {
+		mode, err := parseInputMode(f.InputMode)
+		if err != nil {
+			return nil, err
+		}
+		result.InputMode = mode
+	}
+
+	result.Fullscreen = f.Fullscreen
+	result.WindowScale = f.WindowScale
+	if result.WindowScale < 0 || result.WindowScale > MaxScale {
+		return nil, fmt.Errorf("window_scale must be between 0 and %d got %d", MaxScale, f.WindowScale)
+	}
+
+	for kindName, keyName := range f.Keyboard {
+		kind, err := parseInputKind(kindName)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parseKey(keyName)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Keyboard[kind] = key
+	}
+
+	for kindName, btn := range f.Gamepad {
+		kind, err := parseInputKind(kindName)
+		if err != nil {
+			return nil, err
+		}
+
+		if btn < 0 || btn > int(ebiten.GamepadButtonMax) {
+			return nil, fmt.Errorf("unknown gamepad button %d", btn)
+		}
+
+		result.Gamepad[kind] = ebiten.GamepadButton(btn)
+	}
+
+	return result, nil
+}
+
+// Path is where the settings live in the users config dir
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, dirName, fileName), nil
+}
+
+func LoadFile(path string) (*Settings, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+func SaveFile(path string, s *Settings) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return Save(f, s)
+}
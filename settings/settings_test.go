@@ -0,0 +1,96 @@
+package settings_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/sardap/walk-good-maybe-hd/components"
+	"github.com/sardap/walk-good-maybe-hd/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	s := settings.Default()
+	s.Volume = 0.3
+	s.InputMode = components.InputModeGamepad
+	s.Fullscreen = true
+	s.WindowScale = 4
+	s.Keyboard[components.InputKindJump] = ebiten.KeySpace
+	s.Gamepad[components.InputKindShoot] = 3
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, settings.Save(buf, s))
+	assert.Contains(t, buf.String(), `"jump": "Space"`, "keys should be saved by name")
+
+	loaded, err := settings.Load(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, s, loaded)
+
+	path := filepath.Join(t.TempDir(), "nested", "settings.json")
+	assert.NoError(t, settings.SaveFile(path, s))
+	loaded, err = settings.LoadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, s, loaded)
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Parallel()
+
+	loaded, err := settings.Load(bytes.NewBufferString(`{"version": 1, "keyboard": {"shoot": "C"}}`))
+	assert.NoError(t, err)
+
+	expected := settings.Default()
+	expected.Keyboard[components.InputKindShoot] = ebiten.KeyC
+	assert.Equal(t, expected, loaded, "missing fields should keep their defaults")
+
+	invalid := []string{
+		`{"version": 2}`,
+		`{"version": 1, "volume": 2}`,
+		`{"version": 1, "input_mode": "mouse"}`,
+		`{"version": 1, "window_scale": 100}`,
+		`{"version": 1, "keyboard": {"fly": "Z"}}`,
+		`{"version": 1, "keyboard": {"jump": "NotAKey"}}`,
+		`{"version": 1, "gamepad": {"jump": -1}}`,
+		`not json`,
+	}
+	for _, str := range invalid {
+		_, err := settings.Load(bytes.NewBufferString(str))
+		assert.Error(t, err, str)
+	}
+
+	_, err = settings.Load(bytes.NewBufferString(`{"version": 2}`))
+	assert.ErrorIs(t, err, settings.ErrUnsupportedVersion)
+}
+
+func TestApplyMapping(t *testing.T) {
+	t.Parallel()
+
+	s := settings.Default()
+	s.Keyboard[components.InputKindJump] = ebiten.KeySpace
+	s.Gamepad[components.InputKindJump] = 2
+
+	com := &components.InputComponent{
+		Keyboard: components.DefaultKeyboardInputType(),
+		Gamepad:  components.DefaultGamepadInputType(),
+	}
+	s.ApplyMapping(com)
+	assert.Equal(t, ebiten.KeySpace, com.Keyboard.Mapping[components.InputKindJump])
+	assert.Equal(t, ebiten.GamepadButton(2), com.Gamepad.Mapping[components.InputKindJump])
+	assert.Equal(t, ebiten.KeyX, com.Keyboard.Mapping[components.InputKindShoot])
+
+	// Copies shouldn't share bindings
+	copied := s.Copy()
+	copied.Keyboard[components.InputKindJump] = ebiten.KeyA
+	assert.Equal(t, ebiten.KeySpace, s.Keyboard[components.InputKindJump])
+
+	// Every kind needs a name for the file
+	for kind := components.InputKind(0); kind < components.InputKindLength; kind++ {
+		assert.NotPanics(t, func() {
+			_ = kind.String()
+		})
+	}
+}
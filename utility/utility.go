@@ -1,104 +1,194 @@
-package utility
-
-import (
-	"math/rand"
-	"time"
-
-	"github.com/sardap/walk-good-maybe-hd/math"
-)
-
-func DeltaToDuration(dt float32) time.Duration {
-	var i int = int(dt * float32(time.Second))
-	return time.Duration(i)
-}
-
-func AbsInt(x int) int {
-	if x < 0 {
-		return -x
-	}
-
-	return x
-}
-
-func WrapInt(x, min, max int) (result int) {
-	if x >= max {
-		result = x + min - max
-
-	} else if x < min {
-		result = x + max - min
-	} else {
-		result = x
-	}
-
-	if result > max || result < min {
-		result = WrapInt(result, min, max)
-	}
-	return result
-}
-
-func WrapFloat64(x, min, max float64) float64 {
-	if x >= max {
-		return x + min - max
-	} else if x < min {
-		return x + max - min
-	}
-	return x
-}
-
-func ClampFloat64(x, min, max float64) float64 {
-	if x > max {
-		return max
-	}
-
-	if x < min {
-		return min
-	}
-
-	return x
-}
-
-func ClampVec2(val, min, max math.Vector2) math.Vector2 {
-	return math.Vector2{
-		X: ClampFloat64(val.X, min.X, max.X),
-		Y: ClampFloat64(val.Y, min.Y, max.Y),
-	}
-}
-
-func WrapVec2(val, min, max math.Vector2) math.Vector2 {
-	return math.Vector2{
-		X: WrapFloat64(val.X, min.X, max.X),
-		Y: WrapFloat64(val.Y, min.Y, max.Y),
-	}
-}
-
-func RandRange(rand *rand.Rand, min, max int) int {
-	return rand.Intn(max-min) + min
-}
-
-func RandRangeFloat64(rand *rand.Rand, min, max int) float64 {
-	return float64(rand.Intn(max-min)+min) + rand.Float64()
-}
-
-func ContainsString(ary []string, tags ...string) bool {
-	for _, otherTag := range ary {
-		for _, sTag := range tags {
-			if otherTag == sTag {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-func ContainsInt(ary []int, tags ...int) bool {
-	for _, otherTag := range ary {
-		for _, sTag := range tags {
-			if otherTag == sTag {
-				return true
-			}
-		}
-	}
-
-	return false
-}
+package utility
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/sardap/walk-good-maybe-hd/math"
+)
+
+func DeltaToDuration(dt float32) time.Duration {
+	var i int = int(dt * float32(time.Second))
+	return time.Duration(i)
+}
+
+func AbsInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+func WrapInt(x, min, max int) (result int) {
+	if x >= max {
+		result = x + min - max
+
+	} else if x < min {
+		result = x + max - min
+	} else {
+		result = x
+	}
+
+	if result > max || result < min {
+		result = WrapInt(result, min, max)
+	}
+	return result
+}
+
+func WrapFloat64(x, min, max float64) float64 {
+	if x >= max {
+		return x + min - max
+	} else if x < min {
+		return x + max - min
+	}
+	return x
+}
+
+func ClampFloat64(x, min, max float64) float64 {
+	if x > max {
+		return max
+	}
+
+	if x < min {
+		return min
+	}
+
+	return x
+}
+
+func ClampInt(x, min, max int) int {
+	if x > max {
+		return max
+	}
+
+	if x < min {
+		return min
+	}
+
+	return x
+}
+
+func ClampVec2(val, min, max math.Vector2) math.Vector2 {
+	return math.Vector2{
+		X: ClampFloat64(val.X, min.X, max.X),
+		Y: ClampFloat64(val.Y, min.Y, max.Y),
+	}
+}
+
+func WrapVec2(val, min, max math.Vector2) math.Vector2 {
+	return math.Vector2{
+		X: WrapFloat64(val.X, min.X, max.X),
+		Y: WrapFloat64(val.Y, min.Y, max.Y),
+	}
+}
+
+func RandRange(rand *rand.Rand, min, max int) int {
+	return rand.Intn(max-min) + min
+}
+
+func RandRangeFloat64(rand *rand.Rand, min, max int) float64 {
+	return float64(rand.Intn(max-min)+min) + rand.Float64()
+}
+
+func ContainsString(ary []string, tags ...string) bool {
+	for _, otherTag := range ary {
+		for _, sTag := range tags {
+			if otherTag == sTag {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func ContainsInt(ary []int, tags ...int) bool {
+	for _, otherTag := range ary {
+		for _, sTag := range tags {
+			if otherTag == sTag {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+const (
+	seedAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	seedBits     = 40
+	seedMinChars = seedBits / 5
+)
+
+var (
+	ErrInvalidSeedCode = errors.New("invalid seed code")
+)
+
+// RandomSeed only uses 40 bits so the code fits in 8 characters
+func RandomSeed(rand *rand.Rand) int64 {
+	return rand.Int63n(1<<seedBits-1) + 1
+}
+
+// EncodeSeed turns a seed into Crockford base32 split into groups of four
+func EncodeSeed(seed int64) string {
+	x := uint64(seed)
+
+	var chars []byte
+	for x > 0 || len(chars) < seedMinChars {
+		chars = append([]byte{seedAlphabet[x%32]}, chars...)
+		x /= 32
+	}
+
+	var result strings.Builder
+	for i, c := range chars {
+		if i > 0 && i%4 == 0 {
+			result.WriteByte('-')
+		}
+		result.WriteByte(c)
+	}
+
+	return result.String()
+}
+
+// DecodeSeed is forgiving of case, dashes, spaces and the usual look alikes
+func DecodeSeed(code string) (int64, error) {
+	var result uint64
+	digits := 0
+
+	for _, c := range strings.ToUpper(code) {
+		switch c {
+		case '-', ' ':
+			continue
+		case 'O':
+			c = '0'
+		case 'I', 'L':
+			c = '1'
+		}
+
+		idx := strings.IndexRune(seedAlphabet, c)
+		if idx < 0 {
+			return 0, fmt.Errorf("%w: unexpected %q", ErrInvalidSeedCode, c)
+		}
+
+		if result > (1<<64-1)>>5 {
+			return 0, fmt.Errorf("%w: too long", ErrInvalidSeedCode)
+		}
+		result = result<<5 | uint64(idx)
+		digits++
+	}
+
+	if digits == 0 {
+		return 0, fmt.Errorf("%w: empty", ErrInvalidSeedCode)
+	}
+
+	// A seed of 0 is how a random city is asked for
+	if result == 0 {
+		return 0, fmt.Errorf("%w: zero", ErrInvalidSeedCode)
+	}
+
+	return int64(result), nil
+}